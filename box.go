@@ -0,0 +1,125 @@
+package table
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// boxSeparators are separators of columns in Box.
+const boxSeparators = "|│┃║"
+
+// isBoxSeparator reports whether r is a separator of columns in Box.
+func isBoxSeparator(r rune) bool {
+	return strings.ContainsRune(boxSeparators, r)
+}
+
+// isBorder reports whether s is a border line such as "+----+----+",
+// "----+----" or "├────┼────┤".
+// Border line consists of '+', '-', '=', ':' and box-drawing characters
+// without white spaces, and contains at least one horizontal line.
+func isBorder(s string) bool {
+	s = trim(s)
+	if s == "" || strings.IndexFunc(s, isSpace) != -1 {
+		return false
+	}
+
+	horizontal := false
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("-=─━═", r):
+			horizontal = true
+		case r == '+' || r == ':' || (r >= 0x2500 && r <= 0x257f):
+		default:
+			return false
+		}
+	}
+
+	return horizontal
+}
+
+// parseBoxRow parses s into a row object in Box.
+// When bordered, which means the header starts with a separator, separators
+// at both ends of s are stripped as outer borders. Border line is parsed
+// into a row which has the line as the only value.
+// Returned row is nil if s is empty or white spaces.
+func parseBoxRow(s string, bordered bool) row {
+	s = trim(s)
+	if s == "" {
+		return nil
+	}
+
+	if isBorder(s) {
+		return row{s}
+	}
+
+	var r row
+	var b strings.Builder
+	for _, c := range s {
+		if isBoxSeparator(c) {
+			r = append(r, trim(b.String()))
+			b.Reset()
+			continue
+		}
+
+		b.WriteRune(c)
+	}
+	r = append(r, trim(b.String()))
+
+	// A row of psql, which has no outer borders, can start or end with
+	// a separator next to an empty value.
+	if !bordered {
+		return r
+	}
+
+	if first, _ := utf8.DecodeRuneInString(s); isBoxSeparator(first) {
+		r = r[1:]
+	}
+
+	if last, _ := utf8.DecodeLastRuneInString(s); r.cols() > 1 && isBoxSeparator(last) {
+		r = r[:r.cols()-1]
+	}
+
+	return r
+}
+
+// endsBox reports whether the current line ends the table in Box.
+// Footers such as "(2 rows)" of psql and "2 rows in set" of mysql end
+// the table. A line without separator at the beginning also ends the
+// table whose header has it.
+func (ts *tableScanner) endsBox() bool {
+	s := trim(ts.text)
+	if isFooter(s) {
+		return true
+	}
+
+	first, _ := utf8.DecodeRuneInString(s)
+	return ts.bordered && s != "" && !isBorder(s) && !isBoxSeparator(first)
+}
+
+// isFooter reports whether s is a footer of psql or mysql.
+func isFooter(s string) bool {
+	if t, ok := strings.CutPrefix(s, "("); ok {
+		if t, ok = strings.CutSuffix(t, ")"); ok {
+			return isRowCount(t)
+		}
+	}
+
+	if s == "Empty set" || strings.HasPrefix(s, "Empty set ") {
+		return true
+	}
+
+	n, _, ok := strings.Cut(s, " in set")
+	return ok && isRowCount(n)
+}
+
+// isRowCount reports whether s is like "1 row" or "2 rows".
+func isRowCount(s string) bool {
+	n, unit, ok := strings.Cut(s, " ")
+	if !ok || (unit != "row" && unit != "rows") {
+		return false
+	}
+
+	_, err := strconv.Atoi(n)
+	return err == nil
+}
@@ -0,0 +1,160 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBoxRow(t *testing.T) {
+	tests := []struct {
+		s        string
+		bordered bool
+		want     row
+	}{
+		{"", true, nil},
+		{"| a | b |", true, row{"a", "b"}},
+		{" a | b ", false, row{"a", "b"}},
+		{"│ a │ b │", true, row{"a", "b"}},
+		{"║ a ║ b ║", true, row{"a", "b"}},
+		{"| a |   |", true, row{"a", ""}},
+		{"  2 |", false, row{"2", ""}},
+		{"| x |", false, row{"", "x", ""}},
+		{"| a |", true, row{"a"}},
+		{"| a\\b | c+d |", true, row{"a\\b", "c+d"}},
+		{"+----+----+", true, row{"+----+----+"}},
+		{"----+----", false, row{"----+----"}},
+		{"├────┼────┤", true, row{"├────┼────┤"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := parseBoxRow(tt.s, tt.bordered); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsBorder(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"+----+----+", true},
+		{"----+----", true},
+		{"+====+", true},
+		{"┌───┬───┐", true},
+		{"╞═══╪═══╡", true},
+		{"|:---|", false},
+		{"| - | - |", false},
+		{"+ - +", false},
+		{"+", false},
+		{"│   │", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := isBorder(tt.s); got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecoder_SetDialect_box(t *testing.T) {
+	type user struct {
+		ID   int     `table:"id"`
+		Name *string `table:"name"`
+	}
+
+	tests := []struct {
+		name string
+		s    string
+	}{
+		{
+			"psql",
+			` id | name
+----+-------
+  1 | alice
+  2 |
+(2 rows)
+
+ignored lines...
+`,
+		},
+		{
+			"psql with empty edge cells",
+			` note | id | name
+------+----+-------
+      |  1 | alice
+      |  2 |      
+(2 rows)
+`,
+		},
+		{
+			"mysql",
+			`+----+-------+
+| id | name  |
++----+-------+
+|  1 | alice |
+|  2 | NULL  |
++----+-------+
+2 rows in set (0.00 sec)
+`,
+		},
+		{
+			"unicode",
+			`┌────┬───────┐
+│ id │ name  │
+├────┼───────┤
+│  1 │ alice │
+│  2 │       │
+└────┴───────┘
+ignored lines...
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.s))
+			d.SetDialect(Box)
+			d.SetNull("NULL")
+			var got []user
+			if err := d.DecodeAll(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			alice := "alice"
+			want := []user{{1, &alice}, {2, nil}}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestIsFooter(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"(1 row)", true},
+		{"(12 rows)", true},
+		{"2 rows in set (0.00 sec)", true},
+		{"Empty set (0.00 sec)", true},
+		{"(rows)", false},
+		{"1 row", false},
+		{"a rows in set", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := isFooter(tt.s); got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -14,6 +14,8 @@
 // Empty lines and lines filled with white spaces above header are ignored.
 // Table ends with an empty line or a line filled with white spaces.
 // Once table ends, following lines are ignored.
+// ParseTables and UnmarshalNamed read multiple tables instead.
+// In that case a line like "## title" or "[title]" above a table is its title.
 //
 // Escape sequences can be used in values. Those are "\n" (unescaped into LF),
 // "\\" (\), and "\|" (|).
@@ -21,4 +23,10 @@
 // A row ends with "\" indicates it continues to the next row.
 // In above example 5th row and 6th row are merged when unmarshalling.
 // So the value of "string" column is "def ghi".
+//
+// Decoder also reads tables of other syntaxes, such as Markdown, fixed-width
+// columns and box-drawing, and transposed tables. See Dialect and Orientation.
+//
+// Marshal does the reverse. It writes slice of struct as table string
+// which is unmarshalled into the same values.
 package table
@@ -0,0 +1,248 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Table is a table parsed from table string without binding to struct.
+type Table struct {
+	Name   string // title of the table. Empty if no title
+	Header []string
+	Rows   []Row // rows of table body. Delimiter rows are not included
+
+	// Align is alignment of each column specified by the delimiter row.
+	// Always nil unless dialect is Markdown.
+	Align []Alignment
+
+	// Comments are comments above header and in the table.
+	// Always nil unless comment marker is set by Decoder.SetComment.
+	Comments []Comment
+}
+
+// Row is a row of table body.
+type Row struct {
+	Cells     []string // values of the row. Escape sequences are unescaped
+	StartLine int      // line number of the first line of the row, starting at 1
+	EndLine   int      // line number of the last line of the row
+	Merged    bool     // the row is merged from continued lines
+}
+
+// Alignment is alignment of a column of Markdown table.
+type Alignment int
+
+const (
+	AlignNone   Alignment = iota // "---"
+	AlignLeft                    // ":---"
+	AlignCenter                  // ":---:"
+	AlignRight                   // "---:"
+)
+
+// Comment is a comment in table string.
+type Comment struct {
+	Line     int    // line number of the comment, starting at 1
+	Text     string // text of the comment without marker and surrounding white spaces
+	Trailing bool   // the comment follows a row in the same line
+}
+
+// ParseTable parses table string read from r into Table.
+// Table string is parsed in the same manner as Unmarshal.
+// Returned Table has nil Header if r has no table.
+func ParseTable(r io.Reader) (*Table, error) {
+	return NewDecoder(r).DecodeTable()
+}
+
+// DecodeTable reads the remaining rows of the table into Table.
+func (d *Decoder) DecodeTable() (*Table, error) {
+	d.ts.keepComments = true
+	defer func() { d.ts.keepComments = false }()
+	t := &Table{}
+	for {
+		r, err := d.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		row := Row{
+			Cells:     r,
+			StartLine: d.ts.rowLine,
+			EndLine:   d.ts.line,
+			Merged:    d.ts.merged,
+		}
+
+		// a record of transposed table spans all the lines.
+		if d.transposed {
+			row = Row{Cells: r, StartLine: d.headerLine, EndLine: d.endLine}
+		}
+
+		t.Rows = append(t.Rows, row)
+	}
+
+	if d.header != nil {
+		t.Header = append([]string(nil), d.header...)
+	}
+
+	t.Name = d.ts.title
+	t.Align = d.ts.align
+	t.Comments = d.ts.comments
+	return t, nil
+}
+
+// ParseTables parses all the tables in table string read from r.
+// Tables are separated by empty lines and returned in order.
+//
+// A table can have a title line above its header such as "## users" or
+// "[users]". The title is set to Name of Table.
+func ParseTables(r io.Reader) ([]*Table, error) {
+	return NewDecoder(r).DecodeTables()
+}
+
+// DecodeTables reads all the remaining tables. See ParseTables.
+func (d *Decoder) DecodeTables() ([]*Table, error) {
+	d.ts.titles = true
+	var tables []*Table
+	for {
+		t, err := d.DecodeTable()
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Header == nil {
+			return tables, nil
+		}
+
+		tables = append(tables, t)
+		d.reset()
+	}
+}
+
+// UnmarshalNamed is like Unmarshal except that s contains multiple tables
+// and the table whose title is name is unmarshalled.
+// See ParseTables for title and separation of tables.
+func UnmarshalNamed(s []byte, name string, t interface{}) error {
+	return NewDecoder(bytes.NewReader(s)).DecodeNamed(name, t)
+}
+
+// DecodeNamed skips tables until the table whose title is name and
+// decodes all the rows of it into t. See UnmarshalNamed and DecodeAll.
+func (d *Decoder) DecodeNamed(name string, t interface{}) error {
+	d.ts.titles = true
+	for {
+		if err := d.readHeader(); err == io.EOF {
+			return fmt.Errorf("table: table %q not found", name)
+		} else if err != nil {
+			return err
+		}
+
+		if d.ts.title == name {
+			return d.DecodeAll(t)
+		}
+
+		for {
+			if _, err := d.next(); err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+
+		d.reset()
+	}
+}
+
+// ParseMarkdown parses all the tables embedded in Markdown document read
+// from r. Name of each Table is the text of the nearest preceding heading.
+// Tables in fenced code blocks are skipped.
+// See Decoder.SetDocument for details. Use Decoder to read tables in fenced
+// code blocks.
+func ParseMarkdown(r io.Reader) ([]*Table, error) {
+	return newMarkdownDecoder(r).DecodeTables()
+}
+
+// UnmarshalMarkdown is like Unmarshal except that s is Markdown document
+// and the first table under the heading whose text is heading is unmarshalled.
+// See ParseMarkdown for tables in Markdown document.
+func UnmarshalMarkdown(s []byte, heading string, t interface{}) error {
+	return newMarkdownDecoder(bytes.NewReader(s)).DecodeNamed(heading, t)
+}
+
+// newMarkdownDecoder returns a new decoder that reads Markdown document from r.
+func newMarkdownDecoder(r io.Reader) *Decoder {
+	d := NewDecoder(r)
+	d.SetDialect(Markdown)
+	d.SetDocument(true)
+	return d
+}
+
+// parseHeading returns text of s if s is an ATX heading of Markdown
+// such as "## title ##".
+func parseHeading(s string) (string, bool) {
+	s = trim(s)
+	n := len(s) - len(strings.TrimLeft(s, "#"))
+	if n < 1 || n > 6 {
+		return "", false
+	}
+
+	t := s[n:]
+	if t != "" && !isSpace(rune(t[0])) {
+		return "", false
+	}
+
+	// optional closing sequence
+	t = trim(t)
+	if c := strings.TrimRight(t, "#"); c == "" || isSpace(rune(c[len(c)-1])) {
+		t = c
+	}
+
+	return trim(t), true
+}
+
+// fenceOf returns the leading sequence of s if s opens or closes a fenced
+// code block. Otherwise returns empty string.
+func fenceOf(s string) string {
+	if s == "" || (s[0] != '`' && s[0] != '~') {
+		return ""
+	}
+
+	m := s[:len(s)-len(strings.TrimLeft(s, s[:1]))]
+	if len(m) < 3 {
+		return ""
+	}
+
+	return m
+}
+
+// parseTitle returns title of a table if s is a title line.
+// Title line is like heading of Markdown ("## title") or
+// section of INI file ("[title]"). A line containing sep is not a title
+// line but a row, such as header "# | name".
+func parseTitle(s string, sep rune) (string, bool) {
+	s = trim(s)
+	if strings.ContainsRune(s, sep) {
+		return "", false
+	}
+
+	if strings.HasPrefix(s, "#") {
+		t := strings.TrimLeft(s, "#")
+		if t != "" && isSpace(rune(t[0])) {
+			return trim(t), true
+		}
+
+		return "", false
+	}
+
+	if len(s) > 2 && strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		t := s[1 : len(s)-1]
+		if !strings.ContainsAny(t, "[]") {
+			return trim(t), true
+		}
+	}
+
+	return "", false
+}
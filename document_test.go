@@ -0,0 +1,453 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want *Table
+	}{
+		{
+			"basic",
+			`
+name | age \
+     | note
+---- | ----
+abc  | 12
+def  | \
+     | 34
+-- | --
+ghi  | x\|y
+
+ignored lines...
+`,
+			&Table{
+				Header: []string{"name", "age note"},
+				Rows: []Row{
+					{Cells: []string{"abc", "12"}, StartLine: 5, EndLine: 5},
+					{Cells: []string{"def", "34"}, StartLine: 6, EndLine: 7, Merged: true},
+					{Cells: []string{"ghi", "x|y"}, StartLine: 9, EndLine: 9},
+				},
+			},
+		},
+		{
+			"header only",
+			"a | b",
+			&Table{Header: []string{"a", "b"}},
+		},
+		{
+			"empty",
+			"",
+			&Table{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTable(strings.NewReader(tt.s))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecoder_DecodeTable_comments(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`# users
+name | note
+---- | ----
+# in body
+abc  | \#1 \ # first
+     | x
+`))
+	d.SetComment("#")
+	got, err := d.DecodeTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Table{
+		Header: []string{"name", "note"},
+		Rows: []Row{
+			{Cells: []string{"abc", "#1 x"}, StartLine: 5, EndLine: 6, Merged: true},
+		},
+		Comments: []Comment{
+			{Line: 1, Text: "users"},
+			{Line: 4, Text: "in body"},
+			{Line: 5, Text: "first", Trailing: true},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestDecoder_DecodeTable_markdown(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`
+| name | code       | score |
+|:-----|:----------:|------:|
+| abc  | ` + "`a \\| b`" + ` | 12    |
+`))
+	d.SetDialect(Markdown)
+	got, err := d.DecodeTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Table{
+		Header: []string{"name", "code", "score"},
+		Rows: []Row{
+			{Cells: []string{"abc", "`a | b`", "12"}, StartLine: 4, EndLine: 4},
+		},
+		Align: []Alignment{AlignLeft, AlignCenter, AlignRight},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestDecoder_DecodeTable_transposed(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`
+name | alice | bob
+age  | 20    | 30
+`))
+	d.SetOrientation(Transposed)
+	got, err := d.DecodeTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Table{
+		Header: []string{"name", "age"},
+		Rows: []Row{
+			{Cells: []string{"alice", "20"}, StartLine: 2, EndLine: 3},
+			{Cells: []string{"bob", "30"}, StartLine: 2, EndLine: 3},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestParseTable_error(t *testing.T) {
+	tests := []string{
+		"a | b\nc",
+		"a | b\nc | \\d",
+		"a | \\",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			if _, err := ParseTable(strings.NewReader(tt)); err == nil {
+				t.Fatal("error should be non-nil")
+			}
+		})
+	}
+}
+
+const tablesStr = `
+## users
+name  | age
+----- | ---
+alice | 20
+bob   | 30
+
+[orders]
+
+id | user
+-- | ----
+1  | alice
+
+
+item
+book
+`
+
+func TestParseTables(t *testing.T) {
+	got, err := ParseTables(strings.NewReader(tablesStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Table{
+		{
+			Name:   "users",
+			Header: []string{"name", "age"},
+			Rows: []Row{
+				{Cells: []string{"alice", "20"}, StartLine: 5, EndLine: 5},
+				{Cells: []string{"bob", "30"}, StartLine: 6, EndLine: 6},
+			},
+		},
+		{
+			Name:   "orders",
+			Header: []string{"id", "user"},
+			Rows: []Row{
+				{Cells: []string{"1", "alice"}, StartLine: 12, EndLine: 12},
+			},
+		},
+		{
+			Header: []string{"item"},
+			Rows: []Row{
+				{Cells: []string{"book"}, StartLine: 16, EndLine: 16},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestParseTables_numberColumn(t *testing.T) {
+	got, err := ParseTables(strings.NewReader("[users]\n# | name\n--|--\n1 | alice\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Table{{
+		Name:   "users",
+		Header: []string{"#", "name"},
+		Rows:   []Row{{Cells: []string{"1", "alice"}, StartLine: 4, EndLine: 4}},
+	}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestUnmarshalNamed(t *testing.T) {
+	type order struct {
+		ID   int    `table:"id"`
+		User string `table:"user"`
+	}
+
+	var got []order
+	if err := UnmarshalNamed([]byte(tablesStr), "orders", &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []order{{1, "alice"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	if err := UnmarshalNamed([]byte(tablesStr), "unknown", &got); err == nil {
+		t.Fatal("error should be non-nil")
+	}
+}
+
+const markdownStr = `# Spec
+
+Prose | with pipe is not a table.
+
+## Users
+
+| id | name  |
+|---:|-------|
+| 1  | alice |
+Following prose ends the table.
+
+` + "```" + `
+| id | name |
+|----|------|
+| 9  | code |
+` + "```" + `
+
+| id | name |
+|----|------|
+| 2  | bob  |
+
+## Orders ##
+
+~~~markdown
+| not | table |
+~~~
+
+| id | user  |
+|----|-------|
+| 10 | alice |
+`
+
+func TestParseMarkdown(t *testing.T) {
+	got, err := ParseMarkdown(strings.NewReader(markdownStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Table{
+		{
+			Name:   "Users",
+			Header: []string{"id", "name"},
+			Rows:   []Row{{Cells: []string{"1", "alice"}, StartLine: 9, EndLine: 9}},
+			Align:  []Alignment{AlignRight, AlignNone},
+		},
+		{
+			Name:   "Users",
+			Header: []string{"id", "name"},
+			Rows:   []Row{{Cells: []string{"2", "bob"}, StartLine: 20, EndLine: 20}},
+			Align:  []Alignment{AlignNone, AlignNone},
+		},
+		{
+			Name:   "Orders",
+			Header: []string{"id", "user"},
+			Rows:   []Row{{Cells: []string{"10", "alice"}, StartLine: 30, EndLine: 30}},
+			Align:  []Alignment{AlignNone, AlignNone},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestParseMarkdown_adjacentHeading(t *testing.T) {
+	src := "## a\n| x |\n|---|\n| 1 |\n## b\n| y |\n|---|\n| 2 |\n```\n| z |\n|---|\n```\n"
+	got, err := ParseMarkdown(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Table{
+		{
+			Name:   "a",
+			Header: []string{"x"},
+			Rows:   []Row{{Cells: []string{"1"}, StartLine: 4, EndLine: 4}},
+			Align:  []Alignment{AlignNone},
+		},
+		{
+			Name:   "b",
+			Header: []string{"y"},
+			Rows:   []Row{{Cells: []string{"2"}, StartLine: 8, EndLine: 8}},
+			Align:  []Alignment{AlignNone},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+
+	type y struct {
+		Y int `table:"y"`
+	}
+
+	var ys []y
+	if err := UnmarshalMarkdown([]byte(src), "b", &ys); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ys, []y{{2}}) {
+		t.Fatalf("want %v, got %v", []y{{2}}, ys)
+	}
+}
+
+func TestDecoder_SetFencedCode(t *testing.T) {
+	d := newMarkdownDecoder(strings.NewReader(markdownStr))
+	d.SetFencedCode(true)
+	got, err := d.DecodeTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 4 {
+		t.Fatalf("want 4 tables, got %d", len(got))
+	}
+
+	want := &Table{
+		Name:   "Users",
+		Header: []string{"id", "name"},
+		Rows:   []Row{{Cells: []string{"9", "code"}, StartLine: 15, EndLine: 15}},
+		Align:  []Alignment{AlignNone, AlignNone},
+	}
+
+	if !reflect.DeepEqual(got[1], want) {
+		t.Fatalf("want %+v, got %+v", want, got[1])
+	}
+}
+
+func TestUnmarshalMarkdown(t *testing.T) {
+	type order struct {
+		ID   int    `table:"id"`
+		User string `table:"user"`
+	}
+
+	var got []order
+	if err := UnmarshalMarkdown([]byte(markdownStr), "Orders", &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []order{{10, "alice"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	if err := UnmarshalMarkdown([]byte(markdownStr), "Spec", &got); err == nil {
+		t.Fatal("error should be non-nil")
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   string
+		wantOK bool
+	}{
+		{"# a", "a", true},
+		{"###### a b ", "a b", true},
+		{"## a ##", "a", true},
+		{"## a#", "a#", true},
+		{"#", "", true},
+		{"####### a", "", false},
+		{"#a", "", false},
+		{"[a]", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := parseHeading(tt.s)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("want (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   string
+		wantOK bool
+	}{
+		{"# a", "a", true},
+		{"### a b ", "a b", true},
+		{" [a]", "a", true},
+		{"[ a b ]", "a b", true},
+		{"#a", "", false},
+		{"#", "", false},
+		{"[]", "", false},
+		{"[a|b]", "", false},
+		{"# | name", "", false},
+		{"## a|b", "", false},
+		{"a", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := parseTitle(tt.s, '|')
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("want (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
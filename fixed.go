@@ -0,0 +1,90 @@
+package table
+
+import "strings"
+
+// fixedWidthRow returns the current line sliced at columns.
+// The first row is the header, which determines columns with the ruler
+// row following it.
+func (ts *tableScanner) fixedWidthRow() row {
+	if trim(ts.text) == "" {
+		return nil
+	}
+
+	if ts.columns == nil {
+		ts.columns = wordStarts(ts.text)
+		if next, ok := ts.peek(); ok && isRuler(next) {
+			ts.columns = wordStarts(next)
+		}
+	}
+
+	return sliceColumns(ts.text, ts.columns)
+}
+
+// isRuler reports whether s consists of sequences of '-' separated by
+// white spaces, such as "----- ---".
+func isRuler(s string) bool {
+	s = trim(s)
+	return s != "" && strings.IndexFunc(s, func(r rune) bool { return r != '-' && !isSpace(r) }) == -1
+}
+
+// tabWidth is the interval of tab stops.
+const tabWidth = 8
+
+// columnWidth returns number of columns which r at display position pos
+// occupies. Tab is expanded to the next tab stop.
+func columnWidth(r rune, pos int) int {
+	if r == '\t' {
+		return tabWidth - pos%tabWidth
+	}
+
+	return runeWidth(r)
+}
+
+// wordStarts returns display positions where words in s start.
+func wordStarts(s string) []int {
+	var starts []int
+	pos := 0
+	prev := ' '
+	for _, r := range s {
+		if !isSpace(r) && isSpace(prev) {
+			starts = append(starts, pos)
+		}
+
+		pos += columnWidth(r, pos)
+		prev = r
+	}
+
+	return starts
+}
+
+// sliceColumns slices s into values of columns which start at display
+// positions of starts. The first column starts at the beginning of s and
+// the last column ends at the end of s. A word across the start of a
+// column belongs to the column.
+func sliceColumns(s string, starts []int) row {
+	rs := []rune(s)
+	r := make(row, len(starts))
+	begin := 0 // rune index where the current column begins
+	i, pos := 0, 0
+	for ci := range starts {
+		if ci == len(starts)-1 {
+			r[ci] = trim(string(rs[begin:]))
+			break
+		}
+
+		// rune index where the next column starts
+		for ; i < len(rs) && pos < starts[ci+1]; i++ {
+			pos += columnWidth(rs[i], pos)
+		}
+
+		end := i
+		for end > begin && end < len(rs) && !isSpace(rs[end]) && !isSpace(rs[end-1]) {
+			end--
+		}
+
+		r[ci] = trim(string(rs[begin:end]))
+		begin = end
+	}
+
+	return r
+}
@@ -0,0 +1,114 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSliceColumns(t *testing.T) {
+	tests := []struct {
+		s      string
+		starts []int
+		want   row
+	}{
+		{"a  b  c", []int{0, 3, 6}, row{"a", "b", "c"}},
+		{"  a b c d", []int{0, 4, 6}, row{"a", "b", "c d"}},
+		{"abc", []int{0, 3, 6}, row{"abc", "", ""}},
+		{"", []int{0, 3}, row{"", ""}},
+		{"1 12345 x", []int{0, 4, 8}, row{"1", "12345", "x"}},
+		{"あい b", []int{0, 5}, row{"あい", "b"}},
+		{"ab cdef g", []int{0, 5}, row{"ab", "cdef g"}},
+		{"1\ta", []int{0, 8}, row{"1", "a"}},
+		{"12\t\tb", []int{0, 16}, row{"12", "b"}},
+		{"abcdef g", []int{0, 5}, row{"", "abcdef g"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got := sliceColumns(tt.s, tt.starts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecoder_SetDialect_fixedWidth(t *testing.T) {
+	type process struct {
+		PID  int    `table:"PID"`
+		TTY  string `table:"TTY"`
+		Time string `table:"TIME"`
+		Cmd  string `table:"CMD"`
+	}
+
+	d := NewDecoder(strings.NewReader(`
+    PID TTY          TIME CMD
+      1 pts/0    00:00:00 bash
+  12345 ?        00:01:02 sleep 10
+
+ignored lines...
+`))
+	d.SetDialect(FixedWidth)
+	var got []process
+	if err := d.DecodeAll(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []process{
+		{1, "pts/0", "00:00:00", "bash"},
+		{12345, "?", "00:01:02", "sleep 10"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestDecoder_SetDialect_fixedWidthTab(t *testing.T) {
+	type item struct {
+		ID   int    `table:"id"`
+		Name string `table:"name"`
+	}
+
+	d := NewDecoder(strings.NewReader("id\tname\n1\ta\n1234567\tb c\n"))
+	d.SetDialect(FixedWidth)
+	var got []item
+	if err := d.DecodeAll(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []item{{1, "a"}, {1234567, "b c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestDecoder_SetDialect_fixedWidthRuler(t *testing.T) {
+	type disk struct {
+		Name    string `table:"Filesystem"`
+		Used    int    `table:"Used"`
+		Mounted string `table:"Mounted on"`
+		Note    string `table:"備考"`
+	}
+
+	d := NewDecoder(strings.NewReader(`Filesystem   Used Mounted on 備考
+----------- ----- ---------- ----
+/dev/sda1   10240 /          ルート
+tmpfs           0 /tmp
+`))
+	d.SetDialect(FixedWidth)
+	var got []disk
+	if err := d.DecodeAll(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []disk{
+		{"/dev/sda1", 10240, "/", "ルート"},
+		{"tmpfs", 0, "/tmp", ""},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
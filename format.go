@@ -0,0 +1,135 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Format reformats table string in src so that separators line up in
+// terminal. Display width of East Asian wide characters is counted as 2
+// and that of combining characters is counted as 0.
+//
+// Values are not changed. Delimiter rows are filled with '-' again.
+// Continued rows are kept as they are. Lines above header and
+// lines after the table are not formatted.
+func Format(src []byte) ([]byte, error) {
+	return FormatComment(src, "")
+}
+
+// FormatComment is like Format except that comments start with marker.
+// See Decoder.SetComment for comments. Comment lines and trailing comments
+// are kept as they are and not counted in widths of columns.
+func FormatComment(src []byte, marker string) ([]byte, error) {
+	syn := &syntax{comment: marker}
+	var b bytes.Buffer
+	ls := strings.SplitAfter(string(src), "\n")
+	i := 0
+
+	// lines above header
+	for ; i < len(ls); i++ {
+		r, _, _, err := syn.parseLine(strings.TrimSuffix(ls[i], "\n"), i+1)
+		if err != nil {
+			return nil, fmt.Errorf("table: %w", err)
+		}
+
+		if r != nil {
+			break
+		}
+
+		b.WriteString(ls[i])
+	}
+
+	var lines []line
+	for ; i < len(ls); i++ {
+		s := strings.TrimSuffix(ls[i], "\n")
+		if syn.isComment(s) {
+			lines = append(lines, line{comment: s, commentLine: true})
+			continue
+		}
+
+		r, c, comment, err := syn.parseLine(s, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("table: %w", err)
+		}
+
+		if r == nil {
+			break
+		}
+
+		lines = append(lines, line{row: r, delim: r.isDelim(), cont: c, comment: comment})
+	}
+
+	if err := writeLines(&b, lines, true, syn); err != nil {
+		return nil, fmt.Errorf("table: failed to write row: %v", err)
+	}
+
+	if len(lines) > 0 && i == len(ls) && !bytes.HasSuffix(src, []byte("\n")) {
+		b.Truncate(b.Len() - 1)
+	}
+
+	// lines after the table
+	for ; i < len(ls); i++ {
+		b.WriteString(ls[i])
+	}
+
+	return b.Bytes(), nil
+}
+
+// padRight appends white spaces to s so that display width of s becomes w.
+func padRight(s string, w int) string {
+	if n := w - displayWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+
+	return s
+}
+
+// displayWidth returns number of columns which s occupies in terminal.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+
+	return w
+}
+
+// runeWidth returns number of columns which r occupies in terminal.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.Is(eastAsianWide, r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// eastAsianWide is a table of East Asian Wide (W) and Fullwidth (F) characters.
+// Only major blocks are listed.
+var eastAsianWide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1}, // Hangul Jamo
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1}, // CJK Radicals .. CJK Symbols and Punctuation
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1}, // Hiragana .. CJK Compatibility
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1}, // CJK Unified Ideographs Extension A
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1}, // CJK Unified Ideographs
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1}, // Yi Syllables .. Yi Radicals
+		{Lo: 0xa960, Hi: 0xa97f, Stride: 1}, // Hangul Jamo Extended-A
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1}, // Hangul Syllables
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1}, // CJK Compatibility Ideographs
+		{Lo: 0xfe10, Hi: 0xfe19, Stride: 1}, // Vertical Forms
+		{Lo: 0xfe30, Hi: 0xfe6f, Stride: 1}, // CJK Compatibility Forms .. Small Form Variants
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1}, // Fullwidth Forms
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1}, // Fullwidth Signs
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1}, // Miscellaneous Symbols and Pictographs .. Emoticons
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1}, // Supplemental Symbols and Pictographs
+		{Lo: 0x20000, Hi: 0x2fffd, Stride: 1}, // CJK Unified Ideographs Extension B ..
+		{Lo: 0x30000, Hi: 0x3fffd, Stride: 1}, // CJK Unified Ideographs Extension G ..
+	},
+}
@@ -0,0 +1,151 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			"basic",
+			`
+string|custom||int|文字列
+-|-||-|-
+abc|OK||302|あいうえお
+|NG||-0x20|日本語
+
+ignored | lines
+`,
+			`
+string | custom |   | int   | 文字列
+------ | ------ | - | ----- | ----------
+abc    | OK     |   | 302   | あいうえお
+       | NG     |   | -0x20 | 日本語
+
+ignored | lines
+`,
+		},
+		{
+			"escape and continue",
+			`a | b
+\|\\ | abc\n \
+x | y`,
+			`a    | b
+\|\\ | abc\n \
+x    | y`,
+		},
+		{
+			"combining character",
+			"ee | x\ne\u0301 | y\n",
+			"ee | x\ne\u0301  | y\n",
+		},
+		{
+			"empty",
+			``,
+			``,
+		},
+		{
+			"blank lines only",
+			"\n \n",
+			"\n \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("want\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatComment(t *testing.T) {
+	src := `# users
+name|tag # trailing
+-|-
+# regression for 412
+abc|\#1 \ # continued
+|x
+de|f
+`
+	want := `# users
+name | tag # trailing
+---- | ---
+# regression for 412
+abc  | \#1 \ # continued
+     | x
+de   | f
+`
+	got, err := FormatComment([]byte(src), "#")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want {
+		t.Fatalf("want\n%s\ngot\n%s", want, got)
+	}
+
+	// formatted table is decoded into the same values.
+	d := NewDecoder(strings.NewReader(string(got)))
+	d.SetComment("#")
+	var rows [][]string
+	if err := d.DecodeAll(&rows); err != nil {
+		t.Fatal(err)
+	}
+
+	wantRows := [][]string{{"name", "tag"}, {"abc", "#1 x"}, {"de", "f"}}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Fatalf("want %q, got %q", wantRows, rows)
+	}
+}
+
+func TestFormat_error(t *testing.T) {
+	tests := []string{
+		`\a`,
+		"a | b\n\\a | b",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			if _, err := Format([]byte(tt)); err == nil {
+				t.Fatal("error should be non-nil")
+			}
+		})
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"あいう", 6},
+		{"ｱｲｳ", 3},
+		{"ＡＢ", 4},
+		{"é", 1},
+		{"한국", 4},
+		{"​", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := displayWidth(tt.s); got != tt.want {
+				t.Fatalf("want %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package table
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal returns table string of t.
+// t should be a slice of struct or a pointer to it.
+//
+// Headers are taken from struct field tags in the same manner as Unmarshal.
+// Output consists of a header row, a delimiter row and body rows.
+// Special characters in values are escaped so that the output is
+// unmarshalled into the same values.
+func Marshal(t interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := MarshalWriter(&b, t); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// MarshalWriter is like Marshal except for writing data to io.Writer
+// instead of returning []byte.
+func MarshalWriter(w io.Writer, t interface{}) error {
+	return NewEncoder(w).Encode(t)
+}
+
+// Encoder writes table string to an output stream.
+type Encoder struct {
+	w   io.Writer
+	pad bool
+	syn syntax
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetPadding sets whether values are padded with white spaces
+// so that separators line up in terminal.
+// Display width of East Asian wide characters is counted as 2
+// and that of combining characters is counted as 0.
+func (e *Encoder) SetPadding(pad bool) {
+	e.pad = pad
+}
+
+// SetSeparator sets separator of columns. See Decoder.SetSeparator
+// for valid separators. Encode returns an error for invalid ones.
+// Separators are surrounded by ' ' unless it is '\t'.
+func (e *Encoder) SetSeparator(sep rune) {
+	e.syn.sep = sep
+}
+
+// Encode writes table string of t to the stream.
+// t should be a slice of struct or a pointer to it. See Marshal for details.
+func (e *Encoder) Encode(t interface{}) error {
+	// vXxx represents a value. tXxx represents a type.
+	vSlice := reflect.ValueOf(t)
+	if vSlice.Kind() == reflect.Ptr {
+		vSlice = vSlice.Elem()
+	}
+
+	if vSlice.Kind() != reflect.Slice {
+		return errors.New("table: value of interface{} is not a slice or a pointer of slice")
+	}
+
+	tStruct := vSlice.Type().Elem()
+	if tStruct.Kind() != reflect.Struct {
+		return errors.New("table: value of interface{} is not a slice of struct")
+	}
+
+	if !validSeparator(e.syn.separator()) {
+		return fmt.Errorf("table: invalid separator %q", e.syn.separator())
+	}
+
+	fields, err := typeFields(tStruct)
+	if err != nil {
+		return fmt.Errorf("table: parsing tag: %v", err)
+	}
+
+	header := headerOf(fields)
+	if header.cols() == 0 {
+		return errors.New("table: struct has no field with table tag")
+	}
+
+	if err := checkRepresentable(header); err != nil {
+		return fmt.Errorf("table: header: %v", err)
+	}
+
+	lines := []line{{row: header}, {row: make(row, header.cols()), delim: true}}
+	for i := 0; i < vSlice.Len(); i++ {
+		r, err := marshalStruct(vSlice.Index(i), fields)
+		if err != nil {
+			return fmt.Errorf("table: failed to marshal row: %v", err)
+		}
+
+		if err := checkRepresentable(r); err != nil {
+			return fmt.Errorf("table: row %d: %v", i, err)
+		}
+
+		lines = append(lines, line{row: r})
+	}
+
+	if err := writeLines(e.w, lines, e.pad, &e.syn); err != nil {
+		return fmt.Errorf("table: failed to write row: %v", err)
+	}
+
+	return nil
+}
+
+// Marshaler provides custom marshalling method.
+// It is the counterpart of Unmarshaler.
+// Marshal calls implementation's MarshalTable and escapes returned value.
+type Marshaler interface {
+	MarshalTable() ([]byte, error)
+}
+
+// headerOf returns header row consists of column names of fields.
+func headerOf(fields []field) row {
+	var header row
+	for _, f := range fields {
+		header = append(header, f.tag.name)
+	}
+
+	return header
+}
+
+// checkRepresentable returns non-nil error if r cannot be written
+// as table string which is parsed into the same row.
+func checkRepresentable(r row) error {
+	for _, e := range r {
+		if trim(e) != e {
+			return fmt.Errorf("leading or trailing white space can not be represented: %q", e)
+		}
+	}
+
+	if r.isDelim() {
+		return fmt.Errorf("row is regarded as delimiter: %q", r)
+	}
+
+	return nil
+}
+
+// line is a row to be written.
+type line struct {
+	row   row
+	delim bool // row is a delimiter. Its values are ignored.
+	cont  bool // row continues to the next one.
+
+	comment     string // trailing comment written as it is. The whole line if commentLine
+	commentLine bool   // line is a comment line. row is ignored.
+}
+
+// writeLines writes lines to w with separator of syn. Values are escaped.
+// When pad is true, values are padded so that separators line up.
+func writeLines(w io.Writer, lines []line, pad bool, syn *syntax) error {
+	var widths []int
+	if pad {
+		widths = columnWidths(lines, syn)
+	}
+
+	sep := " " + string(syn.separator()) + " "
+	if syn.separator() == '\t' {
+		sep = "\t"
+	}
+
+	for _, l := range lines {
+		if l.commentLine {
+			if _, err := io.WriteString(w, l.comment+"\n"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		cells := make([]string, l.row.cols())
+		for i, e := range l.row {
+			switch {
+			case l.delim && pad:
+				cells[i] = strings.Repeat("-", widths[i])
+			case l.delim:
+				cells[i] = "---"
+			default:
+				cells[i] = syn.escape(e)
+			}
+
+			if pad && (i < len(cells)-1 || l.cont) {
+				cells[i] = padRight(cells[i], widths[i])
+			}
+		}
+
+		s := strings.Join(cells, sep)
+		if l.cont {
+			s += ` \`
+		} else {
+			s = strings.TrimRight(s, " ")
+		}
+
+		if l.comment != "" {
+			s += " " + l.comment
+		}
+
+		if _, err := io.WriteString(w, s+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// columnWidths returns display width of each column of escaped values.
+// Width is at least 1 so that delimiter has at least one '-'.
+func columnWidths(lines []line, syn *syntax) []int {
+	var widths []int
+	for _, l := range lines {
+		if l.commentLine {
+			continue
+		}
+
+		for i, e := range l.row {
+			if i == len(widths) {
+				widths = append(widths, 1)
+			}
+
+			if l.delim {
+				continue
+			}
+
+			if w := displayWidth(syn.escape(e)); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	return widths
+}
+
+// marshalStruct marshals vStruct into a row.
+// Each field corresponds to each column.
+func marshalStruct(vStruct reflect.Value, fields []field) (row, error) {
+	r := make(row, len(fields))
+	for ci, f := range fields {
+		v, err := vStruct.FieldByIndexErr(f.index)
+		if err != nil {
+			// field of nil pointer to embedded struct is empty.
+			continue
+		}
+
+		s, err := marshalValue(v, f.tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", f.name, err)
+		}
+
+		r[ci] = s
+	}
+
+	return r, nil
+}
+
+// marshalValue formats v into a string.
+// Marshaler is preferred to encoding.TextMarshaler,
+// which is preferred to basic types.
+// t is the tag of the struct field which v belongs to.
+func marshalValue(v reflect.Value, t *tag) (string, error) {
+	// nil is an empty value. Otherwise the pointed value is formatted.
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+
+		return marshalValue(v.Elem(), t)
+	}
+
+	if implements(v, marshalerType) {
+		s, err := marshalMarshalerType(v)
+		if err != nil {
+			return "", fmt.Errorf("marshaling Marshaler: %v", err)
+		}
+
+		return s, nil
+	}
+
+	// time.Time is a basic type to apply layout.
+	if implements(v, textMarshalerType) && v.Type() != timeType {
+		if !v.Type().Implements(textMarshalerType) {
+			v = v.Addr()
+		}
+
+		p, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("marshaling TextMarshaler: %v", err)
+		}
+
+		return string(p), nil
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		return marshalList(v, t)
+	}
+
+	if v.Kind() == reflect.Map {
+		return marshalMap(v, t)
+	}
+
+	s, err := marshalBasicType(v, t)
+	if err != nil {
+		return "", fmt.Errorf("marshaling basic type: %v", err)
+	}
+
+	return s, nil
+}
+
+// marshalList formats elements of v, which is a slice or an array,
+// joining them with separator of t.
+func marshalList(v reflect.Value, t *tag) (string, error) {
+	sep := t.separator()
+	elems := make([]string, v.Len())
+	for i := range elems {
+		e, err := marshalValue(v.Index(i), t)
+		if err != nil {
+			return "", fmt.Errorf("element %d: %v", i, err)
+		}
+
+		if err := checkElement(e, sep); err != nil {
+			return "", fmt.Errorf("element %d: %v", i, err)
+		}
+
+		elems[i] = e
+	}
+
+	if len(elems) == 1 && elems[0] == "" {
+		return "", errors.New("single empty element can not be represented")
+	}
+
+	return strings.Join(elems, joiner(sep)), nil
+}
+
+// marshalMap formats entries of v, which is a map whose key is string,
+// joining them with separator of t. Entries are sorted by key.
+func marshalMap(v reflect.Value, t *tag) (string, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return "", fmt.Errorf("formatting %s: key is not string", v.Type())
+	}
+
+	sep, kvsep := t.separator(), t.keyValueSeparator()
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	entries := make([]string, len(keys))
+	for i, vKey := range keys {
+		k := vKey.String()
+		if err := checkElement(k, sep); err != nil {
+			return "", fmt.Errorf("key %q: %v", k, err)
+		}
+
+		if strings.Contains(k, kvsep) {
+			return "", fmt.Errorf("key %q can not be represented", k)
+		}
+
+		// copy for addressability
+		vValue := reflect.New(v.Type().Elem()).Elem()
+		vValue.Set(v.MapIndex(vKey))
+		val, err := marshalValue(vValue, t)
+		if err != nil {
+			return "", fmt.Errorf("key %q: %v", k, err)
+		}
+
+		if err := checkElement(val, sep); err != nil {
+			return "", fmt.Errorf("key %q: %v", k, err)
+		}
+
+		entries[i] = k + kvsep + val
+	}
+
+	return strings.Join(entries, joiner(sep)), nil
+}
+
+// checkElement returns non-nil error if e cannot be an element
+// separated by sep.
+func checkElement(e, sep string) error {
+	if strings.Contains(e, sep) {
+		return fmt.Errorf("separator %q can not be represented: %q", sep, e)
+	}
+
+	if trim(e) != e {
+		return fmt.Errorf("leading or trailing white space can not be represented: %q", e)
+	}
+
+	return nil
+}
+
+// joiner returns a string which joins elements separated by sep.
+// A white space follows sep for readability unless sep is white spaces.
+func joiner(sep string) string {
+	if trim(sep) == "" {
+		return sep
+	}
+
+	return sep + " "
+}
+
+// implements reports whether v or its address implements t.
+func implements(v reflect.Value, t reflect.Type) bool {
+	return v.Type().Implements(t) || (v.CanAddr() && reflect.PtrTo(v.Type()).Implements(t))
+}
+
+// marshalerType is an object represents type of Marshaler.
+var marshalerType = reflect.TypeOf(new(Marshaler)).Elem()
+
+// textMarshalerType is an object represents type of encoding.TextMarshaler.
+var textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+
+func marshalMarshalerType(v reflect.Value) (string, error) {
+	// calls Addr() for pointer receiver
+	if !v.Type().Implements(marshalerType) {
+		v = v.Addr()
+	}
+
+	p, err := v.Interface().(Marshaler).MarshalTable()
+	if err != nil {
+		return "", err
+	}
+
+	return string(p), nil
+}
+
+func marshalBasicType(v reflect.Value, t *tag) (string, error) {
+	switch v.Type() {
+	case timeType:
+		return formatTime(v.Interface().(time.Time), t), nil
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	}
+
+	switch k := v.Kind(); k {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("formatting %s: unknown type", k)
+	}
+}
+
+// formatTime formats tm with layout and location of t.
+// Default layout is time.RFC3339Nano and default location is UTC,
+// which are the same as those of unmarshalling.
+func formatTime(tm time.Time, t *tag) string {
+	layout := time.RFC3339Nano
+	if t.layout != "" {
+		layout = t.layout
+	}
+
+	loc := time.UTC
+	if t.loc != nil {
+		loc = t.loc
+	}
+	tm = tm.In(loc)
+
+	return tm.Format(layout)
+}
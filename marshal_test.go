@@ -0,0 +1,494 @@
+package table
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type marshalRow struct {
+	Bool      bool    `table:"bool value"`
+	Int       int     `table:"int value"`
+	Uint      uint    `table:"uint value"`
+	Float     float32 `table:"float value"`
+	String    string  `table:"string value"`
+	Mojiretsu string  `table:"文字列 の 値"`
+	Escaped   string  `table:"escaped | value"`
+	Ignored   string
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		t    interface{}
+		want string
+	}{
+		{
+			"basic",
+			[]marshalRow{
+				{true, 302, 7890, 1.234, "abc", "あいうえお", "abc\nd", "x"},
+				{false, -0x20, 3333, -5, "", "日本語", "|\\n|", ""},
+			},
+			`bool value | int value | uint value | float value | string value | 文字列 の 値 | escaped \| value
+--- | --- | --- | --- | --- | --- | ---
+true | 302 | 7890 | 1.234 | abc | あいうえお | abc\nd
+false | -32 | 3333 | -5 |  | 日本語 | \|\\n\|
+`,
+		},
+		{
+			"pointer to slice",
+			&[]marshalRow{
+				{true, 302, 7890, 1.234, "abc", "あいうえお", "abc\nd", "x"},
+			},
+			`bool value | int value | uint value | float value | string value | 文字列 の 値 | escaped \| value
+--- | --- | --- | --- | --- | --- | ---
+true | 302 | 7890 | 1.234 | abc | あいうえお | abc\nd
+`,
+		},
+		{
+			"empty",
+			[]marshalRow{},
+			`bool value | int value | uint value | float value | string value | 文字列 の 値 | escaped \| value
+--- | --- | --- | --- | --- | --- | ---
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("want\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEncoder_SetPadding(t *testing.T) {
+	var b strings.Builder
+	e := NewEncoder(&b)
+	e.SetPadding(true)
+	err := e.Encode([]marshalRow{
+		{true, 302, 7890, 1.234, "abc", "あいうえお", "abc\nd", ""},
+		{false, -0x20, 3333, -5, "", "日本語", "|\\n|", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `bool value | int value | uint value | float value | string value | 文字列 の 値 | escaped \| value
+---------- | --------- | ---------- | ----------- | ------------ | ------------ | ----------------
+true       | 302       | 7890       | 1.234       | abc          | あいうえお   | abc\nd
+false      | -32       | 3333       | -5          |              | 日本語       | \|\\n\|
+`
+	if b.String() != want {
+		t.Fatalf("want\n%s\ngot\n%s", want, b.String())
+	}
+}
+
+func TestEncoder_SetSeparator(t *testing.T) {
+	type sepRow struct {
+		Name string `table:"name"`
+		Note string `table:"note"`
+	}
+
+	rows := []sepRow{{"a\tb", "x|y"}, {"c", "1\t2"}}
+	tests := []struct {
+		sep  rune
+		want string
+	}{
+		{'\t', "name\tnote\n---\t---\na\\\tb\tx|y\nc\t1\\\t2\n"},
+		{';', "name ; note\n--- ; ---\na\tb ; x|y\nc ; 1\t2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sep), func(t *testing.T) {
+			var b strings.Builder
+			e := NewEncoder(&b)
+			e.SetSeparator(tt.sep)
+			if err := e.Encode(rows); err != nil {
+				t.Fatal(err)
+			}
+
+			if b.String() != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, b.String())
+			}
+
+			d := NewDecoder(strings.NewReader(b.String()))
+			d.SetSeparator(tt.sep)
+			var got []sepRow
+			if err := d.DecodeAll(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, rows) {
+				t.Fatalf("want %v, got %v", rows, got)
+			}
+		})
+	}
+
+	for _, sep := range []rune{'\\', 'n', '-', ':', ' ', '\n'} {
+		e := NewEncoder(io.Discard)
+		e.SetSeparator(sep)
+		if err := e.Encode(rows); err == nil {
+			t.Fatalf("%q: error should be non-nil", sep)
+		}
+	}
+}
+
+func TestMarshal_roundTrip(t *testing.T) {
+	want := []marshalRow{
+		{true, 302, 7890, 1.234, "abc", "あいうえお", "abc\nd", ""},
+		{false, -0x20, 3333, -5, "", "日本語", "|\\n|", ""},
+		{false, 0, 0, 0.1, "a|b", "\\", "\n", ""},
+	}
+
+	p, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []marshalRow
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+// upper is a Marshaler with pointer receiver.
+type upper string
+
+func (u *upper) MarshalTable() ([]byte, error) {
+	return []byte(strings.ToUpper(string(*u))), nil
+}
+
+// failing is a Marshaler which always fails.
+type failing struct{}
+
+func (failing) MarshalTable() ([]byte, error) {
+	return nil, errors.New("failing")
+}
+
+func TestMarshal_marshaler(t *testing.T) {
+	type marshalerRow struct {
+		Value   okNg  `table:"value receiver"`
+		Pointer upper `table:"pointer receiver"`
+	}
+
+	got, err := Marshal([]marshalerRow{{true, "a|b"}, {false, "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `value receiver | pointer receiver
+--- | ---
+OK | A\|B
+NG | C
+`
+	if string(got) != want {
+		t.Fatalf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestMarshal_roundTripUnmarshaler(t *testing.T) {
+	want := []testRow{
+		{true, 302, 7890, 1.234, "abc", "あいうえお", true, "abc\nd"},
+		{false, -0x20, 3333, -5, "", "日本語", false, "|\\n|"},
+	}
+
+	p, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []testRow
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestMarshal_roundTripText(t *testing.T) {
+	type textRow struct {
+		Addr netip.Addr `table:"addr"`
+		IP   net.IP     `table:"ip"`
+		Big  big.Int    `table:"big"`
+	}
+
+	want := []textRow{
+		{netip.MustParseAddr("192.0.2.1"), net.ParseIP("2001:db8::1"), *big.NewInt(0).Lsh(big.NewInt(1), 100)},
+	}
+
+	p, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(p), "192.0.2.1 | 2001:db8::1 | 1267650600228229401496703205376") {
+		t.Fatalf("unexpected table string:\n%s", p)
+	}
+
+	var got []textRow
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestMarshal_time(t *testing.T) {
+	type timeRow struct {
+		Default  time.Time     `table:"default"`
+		Layout   time.Time     `table:"layout,layout=2006/01/02 15:04"`
+		TZ       time.Time     `table:"tz,layout=2006/01/02 15:04,tz=Asia/Tokyo"`
+		Duration time.Duration `table:"duration"`
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	jst := time.Date(2020, 1, 2, 12, 4, 5, 6, tokyo)
+	rows := []timeRow{{tm, tm, tm, 90 * time.Second}, {jst, jst, jst, 0}}
+	got, err := Marshal(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `default | layout | tz | duration
+--- | --- | --- | ---
+2020-01-02T03:04:05.000000006Z | 2020/01/02 03:04 | 2020/01/02 12:04 | 1m30s
+2020-01-02T03:04:05.000000006Z | 2020/01/02 03:04 | 2020/01/02 12:04 | 0s
+`
+	if string(got) != want {
+		t.Fatalf("want\n%s\ngot\n%s", want, got)
+	}
+
+	// values without zone are UTC in both directions.
+	var back []timeRow
+	if err := Unmarshal(got, &back); err != nil {
+		t.Fatal(err)
+	}
+
+	if !back[1].Layout.Equal(jst.Truncate(time.Minute)) {
+		t.Fatalf("want %v, got %v", jst.Truncate(time.Minute), back[1].Layout)
+	}
+}
+
+func TestMarshal_pointer(t *testing.T) {
+	i, str, tm, c := -32, "abc", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), okNg(true)
+	want := []pointerRow{
+		{Name: "a"},
+		{&i, &str, &tm, &c, "b"},
+	}
+
+	p, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStr := `int | string | time | custom | name
+--- | --- | --- | --- | ---
+ |  |  |  | a
+-32 | abc | 2020-01-02T03:04:05Z | OK | b
+`
+	if string(p) != wantStr {
+		t.Fatalf("want\n%s\ngot\n%s", wantStr, p)
+	}
+
+	var got []pointerRow
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestMarshal_list(t *testing.T) {
+	one, three := 1, 3
+	want := []listRow{{
+		[]string{"a", "b", "c d"},
+		[3]int{1, 2, 0},
+		[]*int{&one, nil, &three},
+		[]okNg{true, false, true},
+		[]time.Time{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+		nil,
+	}}
+
+	p, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStr := `strings | ints | pointers | customs | times | empty
+--- | --- | --- | --- | --- | ---
+a, b, c d | 1; 2; 0 | 1, , 3 | OK NG OK | 2020-01-02, 2021-03-04 |
+`
+	if string(p) != wantStr {
+		t.Fatalf("want\n%s\ngot\n%s", wantStr, p)
+	}
+
+	var got []listRow
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestMarshal_map(t *testing.T) {
+	ok := okNg(true)
+	want := []mapRow{{
+		map[string]string{"k2": "v=2", "k1": "v1"},
+		map[string]int{"https": 443, "http": 80},
+		map[string]time.Duration{"b": time.Minute, "a": time.Second},
+		nil,
+		map[string]*okNg{"y": nil, "x": &ok},
+	}}
+
+	p, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStr := `labels | ports | timeout | empty | pointer
+--- | --- | --- | --- | ---
+k1=v1, k2=v=2 | http:80; https:443 | a=1s; b=1m0s |  | x=OK, y=
+`
+	if string(p) != wantStr {
+		t.Fatalf("want\n%s\ngot\n%s", wantStr, p)
+	}
+
+	var got []mapRow
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestMarshal_nested(t *testing.T) {
+	want := []nestedRow{{
+		nestedBase: nestedBase{ID: 1},
+		Input:      nestedReq{"GET", "/"},
+		Want:       nestedResp{200, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}}
+
+	p, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStr := `id | in.method | in.path | want.status | want.time
+--- | --- | --- | --- | ---
+1 | GET | / | 200 | 2020-01-02T03:04:05Z
+`
+	if string(p) != wantStr {
+		t.Fatalf("want\n%s\ngot\n%s", wantStr, p)
+	}
+
+	var got []nestedRow
+	if err := Unmarshal(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestMarshal_embeddedPointer(t *testing.T) {
+	p, err := Marshal([]nestedPtrRow{{&NestedPtr{"1"}, "2"}, {nil, "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `x | y
+--- | ---
+1 | 2
+ | 3
+`
+	if string(p) != want {
+		t.Fatalf("want\n%s\ngot\n%s", want, p)
+	}
+}
+
+func TestMarshal_error(t *testing.T) {
+	type unknown struct {
+		C complex64 `table:"complex"`
+	}
+	type untagged struct {
+		S string
+	}
+	type single struct {
+		S string `table:"s"`
+	}
+	type failingRow struct {
+		F failing `table:"f"`
+	}
+	type list struct {
+		L []string `table:"l"`
+		S string   `table:"s"`
+	}
+
+	tests := []struct {
+		name string
+		t    interface{}
+	}{
+		{"nil", nil},
+		{"int", 123},
+		{"struct", marshalRow{}},
+		{"slice of int", []int{1}},
+		{"slice of pointer to struct", []*marshalRow{}},
+		{"unknown type", []unknown{{1}}},
+		{"no tagged field", []untagged{{"a"}}},
+		{"leading white space", []single{{" a"}}},
+		{"trailing white space", []single{{"a\t"}}},
+		{"empty row", []single{{""}}},
+		{"delimiter row", []single{{"--"}}},
+		{"Marshaler error", []failingRow{{}}},
+		{"separator in element", []list{{[]string{"a,b"}, "s"}}},
+		{"white space in element", []list{{[]string{"a", " b"}, "s"}}},
+		{"single empty element", []list{{[]string{""}, "s"}}},
+		{"key-value separator in key", []struct {
+			M map[string]int `table:"m"`
+		}{{map[string]int{"a=b": 1}}}},
+		{"map key is not string", []struct {
+			M map[int]int `table:"m"`
+		}{{map[int]int{1: 1}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.t); err == nil {
+				t.Fatal("error should be non-nil")
+			}
+		})
+	}
+}
@@ -1,65 +1,174 @@
 package table
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
+	"io"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // row represents a row in table.
 type row []string
 
-// parseRow parses s into a row object.
+// syntax is a set of options of table string syntax.
+type syntax struct {
+	dialect Dialect
+	comment string // comment marker. Empty if comments are not allowed
+	sep     rune   // separator of columns. 0 for default
+}
+
+// separator returns separator of columns.
+func (syn *syntax) separator() rune {
+	if syn.sep == 0 {
+		return '|'
+	}
+
+	return syn.sep
+}
+
+// validSeparator reports whether r can be a separator of columns.
+// 'n' is used by escape sequence `\n`. '-' and ':' are used by
+// delimiter rows.
+func validSeparator(r rune) bool {
+	switch r {
+	case '\\', 'n', '-', ':', utf8.RuneError:
+		return false
+	}
+
+	return r == '\t' || !unicode.IsSpace(r)
+}
+
+// defaultSyntax is syntax without options.
+var defaultSyntax = &syntax{}
+
+// isComment reports whether s is a comment line.
+func (syn *syntax) isComment(s string) bool {
+	return syn.comment != "" && strings.HasPrefix(trim(s), syn.comment)
+}
+
+// commentText returns text of comment line s without marker.
+func (syn *syntax) commentText(s string) string {
+	return trim(strings.TrimPrefix(trim(s), syn.comment))
+}
+
+// parseRow parses s into a row object with default syntax.
 // Returned bool indicates that the row expects to continue to the next one.
 // Returned row and error are nil if s is empty or white spaces.
 func parseRow(s string) (row, bool, error) {
-	rs := newRowScanner(s)
+	r, c, _, err := defaultSyntax.parseRow(s)
+	return r, c, err
+}
+
+// parseRow is like parseRow function except for parsing s with syn.
+// Returned string is a trailing comment as it is, including marker.
+// It is empty if s has no trailing comment.
+func (syn *syntax) parseRow(s string) (row, bool, string, error) {
+	rs := newRowScanner(s, syn)
 	var row row
 	var cont bool
+	var comment string
 	var b strings.Builder
+	var leading, trailing bool // row starts or ends with separator
 	for {
 		t := rs.scan()
+		if t.typ != eof && t.typ != commentTok && (t.typ != text || trim(t.value) != "") {
+			trailing = t.typ == separator
+		}
+
 		switch t.typ {
 		case illegal:
-			return nil, false, fmt.Errorf("scanned token %v", t)
+			return nil, false, "", &ParseError{Pos: t.pos + 1, Err: fmt.Errorf("illegal escape sequence %q", t.value)}
 		case eof:
 			tr := trim(b.String())
 			if tr == "" && row == nil {
-				return nil, cont, nil
+				return nil, cont, comment, nil
 			}
-			return append(row, tr), cont, nil
+
+			// Markdown has optional separators at both ends of row.
+			if syn.dialect == Markdown {
+				if !trailing {
+					row = append(row, tr)
+				}
+
+				if leading {
+					row = row[1:]
+				}
+
+				if len(row) == 0 {
+					return nil, cont, comment, nil
+				}
+
+				return row, cont, comment, nil
+			}
+
+			return append(row, tr), cont, comment, nil
 		case text:
 			b.WriteString(t.value)
-		case pipe:
+		case separator:
+			if row == nil && trim(b.String()) == "" {
+				leading = true
+			}
+
 			row = append(row, trim(b.String()))
 			b.Reset()
 		case escBackslash:
 			b.WriteString("\\")
 		case escNewline:
 			b.WriteString("\n")
-		case escPipe:
-			b.WriteString("|")
+		case escSeparator:
+			b.WriteString(t.value[1:])
+		case escComment:
+			b.WriteString(t.value[1:])
 		case escEOF:
 			cont = true
+		case commentTok:
+			comment = trim(t.value)
 		default:
-			return nil, false, fmt.Errorf("scanned token %v", t)
+			return nil, false, "", fmt.Errorf("scanned token %v", t)
 		}
 	}
 }
 
+// escaper replaces special characters with escape sequences
+// which parseRow understands.
+var escaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", `\n`)
+
+// escape returns s whose special characters are escaped.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// escape is like escape function except for escaping separator of syn
+// instead of '|'. The first character of comment marker is also escaped.
+func (syn *syntax) escape(s string) string {
+	sep := syn.separator()
+	if sep == '|' && syn.comment == "" {
+		return escape(s)
+	}
+
+	oldnew := []string{`\`, `\\`, string(sep), `\` + string(sep), "\n", `\n`}
+	if syn.comment != "" {
+		c, _ := utf8.DecodeRuneInString(syn.comment)
+		oldnew = append(oldnew, string(c), `\`+string(c))
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(s)
+}
+
 type tokenType int
 
 const (
 	illegal tokenType = iota
 	eof
 	text
-	pipe         // |
+	separator    // '|' by default
 	escBackslash // \\
 	escNewline   // \n
-	escPipe      // \|
+	escSeparator // \|
 	escEOF       // \<EOF>
+	escComment   // \ followed by the first character of comment marker
+	commentTok   // comment marker and following characters
 )
 
 func (tt tokenType) String() string {
@@ -70,16 +179,20 @@ func (tt tokenType) String() string {
 		return "EOF"
 	case text:
 		return "TEXT"
-	case pipe:
-		return "PIPE"
+	case separator:
+		return "SEPARATOR"
 	case escBackslash:
 		return "ESCAPE_BACKSLASH"
 	case escNewline:
 		return "ESCAPE_NEWLINE"
-	case escPipe:
-		return "ESCAPE_PIPE"
+	case escSeparator:
+		return "ESCAPE_SEPARATOR"
 	case escEOF:
 		return "ESCAPE_EOF"
+	case escComment:
+		return "ESCAPE_COMMENT"
+	case commentTok:
+		return "COMMENT"
 	default:
 		return "UNKNOWN"
 	}
@@ -89,6 +202,7 @@ func (tt tokenType) String() string {
 type token struct {
 	typ   tokenType
 	value string
+	pos   int // rune offset of the token in row string
 }
 
 func (t *token) String() string {
@@ -97,59 +211,133 @@ func (t *token) String() string {
 
 // rowScanner scans tokens in row string.
 type rowScanner struct {
-	reader *bufio.Reader
+	src  string
+	syn  *syntax
+	off  int // byte offset of the next rune
+	pos  int // number of runes read so far
+	last int // byte size of the last rune read
 }
 
-func newRowScanner(s string) *rowScanner {
-	return &rowScanner{
-		reader: bufio.NewReader(bytes.NewBufferString(s)),
-	}
+func newRowScanner(s string, syn *syntax) *rowScanner {
+	return &rowScanner{src: s, syn: syn}
 }
 
 // scan returns a token in row string.
 func (s *rowScanner) scan() *token {
-	r, _, err := s.reader.ReadRune()
+	pos := s.pos
+	if s.atComment() {
+		t := &token{commentTok, s.src[s.off:], pos}
+		for {
+			if _, err := s.read(); err != nil {
+				return t
+			}
+		}
+	}
+
+	r, err := s.read()
 	if err != nil {
-		return &token{eof, ""}
+		return &token{eof, "", pos}
 	}
 
-	if r == '|' {
-		return &token{pipe, "|"}
+	sep := s.syn.separator()
+	if r == sep {
+		return &token{separator, string(r), pos}
+	}
+
+	if r == '\\' && s.syn.dialect == Markdown {
+		return s.scanMarkdownEscape(pos)
 	}
 
 	if r == '\\' {
-		r2, _, err := s.reader.ReadRune()
+		r2, err := s.read()
 		if err != nil {
-			_ = s.reader.UnreadRune()
-			return &token{escEOF, "\\"}
+			return &token{escEOF, "\\", pos}
 		}
 
-		switch r2 {
-		case '\\':
-			return &token{escBackslash, "\\\\"}
-		case '|':
-			return &token{escPipe, "\\|"}
-		case 'n':
-			return &token{escNewline, "\\n"}
+		switch {
+		case r2 == '\\':
+			return &token{escBackslash, "\\\\", pos}
+		case r2 == sep:
+			return &token{escSeparator, "\\" + string(r2), pos}
+		case r2 == 'n':
+			return &token{escNewline, "\\n", pos}
+		case s.syn.comment != "" && strings.HasPrefix(s.syn.comment, string(r2)):
+			return &token{escComment, "\\" + string(r2), pos}
+		case isSpace(r2) && s.syn.comment != "" &&
+			strings.HasPrefix(strings.TrimLeftFunc(s.src[s.off:], isSpace), s.syn.comment):
+			// continues to the next row with trailing comment
+			s.unread()
+			return &token{escEOF, "\\", pos}
 		default:
-			return &token{illegal, "\\" + string(r2)}
+			return &token{illegal, "\\" + string(r2), pos}
 		}
 	}
 
-	_ = s.reader.UnreadRune()
-	buf := &bytes.Buffer{}
+	s.unread()
+	var b strings.Builder
 	for {
-		r, _, err = s.reader.ReadRune()
+		if s.atComment() {
+			return &token{text, b.String(), pos}
+		}
+
+		r, err = s.read()
 		if err != nil {
-			return &token{text, buf.String()}
+			return &token{text, b.String(), pos}
 		}
 
-		if r == '|' || r == '\\' {
-			_ = s.reader.UnreadRune()
-			return &token{text, buf.String()}
+		if r == sep || r == '\\' {
+			s.unread()
+			return &token{text, b.String(), pos}
 		}
 
-		buf.WriteRune(r)
+		b.WriteRune(r)
+	}
+}
+
+// scanMarkdownEscape returns a token of backslash at pos in Markdown.
+// Only `\|` and escape of comment marker are unescaped. Otherwise a backslash
+// and the following character are text as they are.
+func (s *rowScanner) scanMarkdownEscape(pos int) *token {
+	r2, err := s.read()
+	if err != nil {
+		return &token{text, "\\", pos}
+	}
+
+	switch {
+	case r2 == s.syn.separator():
+		return &token{escSeparator, "\\" + string(r2), pos}
+	case s.syn.comment != "" && strings.HasPrefix(s.syn.comment, string(r2)):
+		return &token{escComment, "\\" + string(r2), pos}
+	default:
+		return &token{text, "\\" + string(r2), pos}
+	}
+}
+
+// atComment reports whether comment marker starts at the current position.
+func (s *rowScanner) atComment() bool {
+	return s.syn.comment != "" && strings.HasPrefix(s.src[s.off:], s.syn.comment)
+}
+
+// read reads a rune counting the position.
+func (s *rowScanner) read() (rune, error) {
+	if s.off >= len(s.src) {
+		return 0, io.EOF
+	}
+
+	r, size := utf8.DecodeRuneInString(s.src[s.off:])
+	s.off += size
+	s.last = size
+	s.pos++
+	return r, nil
+}
+
+// unread unreads the last rune read counting the position.
+// Only one rune can be unread after read.
+func (s *rowScanner) unread() {
+	if s.last > 0 {
+		s.off -= s.last
+		s.last = 0
+		s.pos--
 	}
 }
 
@@ -165,6 +353,47 @@ func (r row) index(v string) int {
 	return -1
 }
 
+// isDelim returns true if r is a delimiter row in syn.
+// Delimiter row of Markdown can have ':' at both ends of each value.
+// Delimiter row of Box is a border line.
+func (syn *syntax) isDelim(r row) bool {
+	if syn.dialect == Box {
+		return r.cols() == 1 && isBorder(r[0])
+	}
+
+	if syn.dialect != Markdown {
+		return r.isDelim()
+	}
+
+	for _, e := range r {
+		e = strings.TrimSuffix(strings.TrimPrefix(trim(e), ":"), ":")
+		if e == "" || strings.IndexFunc(e, notDelim) != -1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// alignments returns alignments of columns specified by delimiter row r.
+func alignments(r row) []Alignment {
+	align := make([]Alignment, r.cols())
+	for i, e := range r {
+		e = trim(e)
+		left, right := strings.HasPrefix(e, ":"), strings.HasSuffix(e, ":")
+		switch {
+		case left && right:
+			align[i] = AlignCenter
+		case left:
+			align[i] = AlignLeft
+		case right:
+			align[i] = AlignRight
+		}
+	}
+
+	return align
+}
+
 // isDelim returns true if r is a delimiter row.
 // Delimiter row is consist of sequence of '-' and white spaces.
 func (r row) isDelim() bool {
@@ -182,6 +411,16 @@ func (r row) cols() int {
 	return len(r)
 }
 
+// fit returns r with n columns. Missing columns are empty and excess
+// columns are dropped.
+func (r row) fit(n int) row {
+	if r.cols() >= n {
+		return r[:n]
+	}
+
+	return append(r, make(row, n-r.cols())...)
+}
+
 // merge merges o into r.
 // Values in corresponding column of two rows are merged
 // inserting whitespace between them. Returns non-nil error
@@ -1,6 +1,7 @@
 package table
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -96,6 +97,119 @@ func TestParseRow_error(t *testing.T) {
 	}
 }
 
+func TestParseRow_errorPos(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{`\a`, 1},
+		{`a\ `, 2},
+		{`あ|い\_`, 4},
+		{`\\\|\n\r`, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			_, _, err := parseRow(tt.s)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error should be *ParseError: %v", err)
+			}
+
+			if pe.Pos != tt.want {
+				t.Fatalf("want %d, got %d", tt.want, pe.Pos)
+			}
+		})
+	}
+}
+
+func TestSyntax_parseRow_comment(t *testing.T) {
+	tests := []struct {
+		marker      string
+		s           string
+		wantRow     row
+		wantMerge   bool
+		wantComment string
+	}{
+		{"#", `a | b # note`, row{"a", "b"}, false, "# note"},
+		{"#", `a | b #`, row{"a", "b"}, false, "#"},
+		{"#", `a | b#c`, row{"a", "b"}, false, "#c"},
+		{"#", `a | \#1`, row{"a", "#1"}, false, ""},
+		{"#", `a | \#1 # #2`, row{"a", "#1"}, false, "# #2"},
+		{"#", `a | b \ # note`, row{"a", "b"}, true, "# note"},
+		{"#", `a | b \`, row{"a", "b"}, true, ""},
+		{"//", `a | b // note`, row{"a", "b"}, false, "// note"},
+		{"//", `a | http:\//x`, row{"a", "http://x"}, false, ""},
+		{"//", `a | b/c`, row{"a", "b/c"}, false, ""},
+		{"", `a | b # note`, row{"a", "b # note"}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s[%s]", tt.marker, tt.s), func(t *testing.T) {
+			syn := &syntax{comment: tt.marker}
+			gotRow, gotMerge, gotComment, err := syn.parseRow(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotRow, tt.wantRow) {
+				t.Fatalf("row: want %q, got %q", tt.wantRow, gotRow)
+			}
+
+			if gotMerge != tt.wantMerge {
+				t.Fatalf("merge: want %v, got %v", tt.wantMerge, gotMerge)
+			}
+
+			if gotComment != tt.wantComment {
+				t.Fatalf("comment: want %q, got %q", tt.wantComment, gotComment)
+			}
+		})
+	}
+
+	// escape of comment marker is illegal when comments are disabled
+	if _, _, _, err := defaultSyntax.parseRow(`\#`); err == nil {
+		t.Fatal("error should be non-nil")
+	}
+}
+
+func TestSyntax_parseRow_markdown(t *testing.T) {
+	tests := []struct {
+		s    string
+		want row
+	}{
+		{`| a | b |`, row{"a", "b"}},
+		{`| a | b`, row{"a", "b"}},
+		{`a | b |`, row{"a", "b"}},
+		{`a | b`, row{"a", "b"}},
+		{`|a|`, row{"a"}},
+		{`| | b |`, row{"", "b"}},
+		{`| a | |`, row{"a", ""}},
+		{`|`, nil},
+		{`| a \|`, row{"a |"}},
+		{"| `x \\| y` | b |", row{"`x | y`", "b"}},
+		{`| C:\dir | \n | a\ |`, row{`C:\dir`, `\n`, `a\`}},
+		{`| a \`, row{`a \`}},
+	}
+
+	syn := &syntax{dialect: Markdown}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, cont, _, err := syn.parseRow(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+
+			if cont {
+				t.Fatal("Markdown row should not continue")
+			}
+		})
+	}
+}
+
 func TestRow_isDelim(t *testing.T) {
 	tests := []struct {
 		row  row
@@ -122,6 +236,29 @@ func TestRow_isDelim(t *testing.T) {
 	}
 }
 
+func TestSyntax_isDelim_markdown(t *testing.T) {
+	tests := []struct {
+		r    row
+		want bool
+	}{
+		{row{"---", ":---", ":---:", "---:"}, true},
+		{row{" :-: "}, true},
+		{row{":"}, false},
+		{row{""}, false},
+		{row{"::-"}, false},
+		{row{"-:-"}, false},
+	}
+
+	syn := &syntax{dialect: Markdown}
+	for _, tt := range tests {
+		t.Run(tt.r.String(), func(t *testing.T) {
+			if got := syn.isDelim(tt.r); got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestRow_merge(t *testing.T) {
 	tests := []struct {
 		to, from, want row
@@ -172,3 +309,33 @@ func TestRow_merge_error(t *testing.T) {
 		})
 	}
 }
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{``, ``},
+		{`a`, `a`},
+		{`\`, `\\`},
+		{`|`, `\|`},
+		{"\n", `\n`},
+		{"a\\|\nb", `a\\\|\nb`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := escape(tt.s); got != tt.want {
+				t.Fatalf("want %q, got %q", tt.want, got)
+			}
+
+			r, _, err := parseRow(escape(tt.s))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.s != "" && (r.cols() != 1 || r[0] != tt.s) {
+				t.Fatalf("parsed %q, want %q", r, tt.s)
+			}
+		})
+	}
+}
@@ -3,11 +3,16 @@ package table
 import (
 	"bufio"
 	"bytes"
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Unmarshal parses s as table string then sets parsed objects to t.
@@ -15,13 +20,68 @@ import (
 //
 // Headers are bound to struct field tags.
 // Tag format is as follows:
-//    `table:"column name"`
+//
+//	`table:"column name"`
+//
 // When header corresponds to "column name" is found,
 // element of the column is parsed and the value is set to a struct field of the tag.
+//
+// Options follow column name separated by ','.
+//
+//	`table:"created,layout=2006-01-02,tz=Asia/Tokyo"`
+//	`table:"port,default=80"`
+//
+// default is a value used when the value is empty.
+// layout is a layout of time.Time. Default is time.RFC3339.
+// tz is a location name of time.Time used when the value has no time zone.
+// Default is UTC. time.Duration is parsed by time.ParseDuration.
+//
+// sep is a separator of elements of slice, array and map. Default is ",".
+// kvsep is a separator of key and value of map. Default is "=".
+//
+// A field of struct type with table tag is nested. Its fields are bound to
+// columns whose names are joined with '.' such as "in.method".
+// An embedded struct or pointer to struct without table tag is flattened
+// like encoding/json. A nil pointer is allocated when unmarshalling.
+//
+// A field of pointer type is set to nil when the value is empty.
+// Otherwise the value is parsed into newly allocated object of pointed type.
+// A field of slice or array type is parsed from elements separated by sep.
+// A field of map type whose key is string is parsed from entries separated
+// by sep, each of which is key and value separated by kvsep.
+// Each element is parsed in the same manner as a field.
 func Unmarshal(s []byte, t interface{}) error {
 	return UnmarshalReader(bytes.NewReader(s), t)
 }
 
+// Parse parses s as table string and returns parsed rows as slice of T.
+// T should be a struct type. See Unmarshal for binding of headers.
+func Parse[T any](s []byte) ([]T, error) {
+	return ParseReader[T](bytes.NewReader(s))
+}
+
+// ParseReader is like Parse except for parsing data from io.Reader
+// instead of []byte.
+func ParseReader[T any](r io.Reader) ([]T, error) {
+	var t []T
+	if err := UnmarshalReader(r, &t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// MustParse is like Parse but panics if s cannot be parsed.
+// It simplifies writing table driven tests.
+func MustParse[T any](s []byte) []T {
+	t, err := Parse[T](s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
 // Unmarshaler provides custom unmarshalling method.
 // An implementation is assumed to be fields of struct which is underlying
 // type of Unmarshal's second parameter.
@@ -34,7 +94,183 @@ type Unmarshaler interface {
 
 // UnmarshalReader is like Unmarshal except for parsing data from io.Reader
 // instead of []byte.
+//
+// In addition to a pointer to slice of struct, t can be a pointer to
+// []map[string]string or [][]string for tables whose columns are not known
+// in advance. A map has header values as keys. [][]string has the header
+// as the first element.
 func UnmarshalReader(s io.Reader, t interface{}) error {
+	return NewDecoder(s).DecodeAll(t)
+}
+
+// Decoder reads table string from an input stream and decodes its rows
+// one by one.
+type Decoder struct {
+	ts         *tableScanner
+	header     row
+	headerLine int
+	tStruct    reflect.Type
+	fields     []field
+	mode       ErrorMode
+	null       string // value regarded as null. Empty for none
+	err        error  // error which every following Decode returns
+
+	orientation Orientation
+	transposed  bool  // the table is transposed into header and records
+	records     []row // remaining records of transposed table
+	fieldLines  []int // line numbers of header values of transposed table
+	endLine     int   // line number of the last line of transposed table
+	taken       int   // number of records consumed
+}
+
+// ErrorMode specifies how DecodeAll handles a row which fails to be decoded.
+type ErrorMode int
+
+const (
+	// StopOnError stops decoding at the first error. This is the default.
+	StopOnError ErrorMode = iota
+
+	// SkipOnError skips invalid rows and continues decoding.
+	SkipOnError
+
+	// ZeroOnError stores zero values for invalid rows and continues decoding.
+	ZeroOnError
+)
+
+// Dialect specifies syntax of table string.
+type Dialect int
+
+const (
+	// Plain is the syntax described in package document. This is the default.
+	Plain Dialect = iota
+
+	// Markdown is the syntax of tables of GitHub Flavored Markdown.
+	// Pipes at both ends of rows are optional. Delimiter row can have ':'
+	// at both ends of values to specify alignment.
+	// Only `\|` is an escape sequence, which represents '|' even in inline
+	// code. Other backslashes are kept as they are. Rows are not continued.
+	// Missing cells of a row are empty and excess cells are ignored.
+	Markdown
+
+	// FixedWidth is the syntax of white space aligned columns without
+	// separators, such as output of ps and kubectl.
+	// Columns start at the words of the header or the ruler row like
+	// "----- ---" following the header. Header values can contain white
+	// spaces only with the ruler row. Body rows are sliced at the columns.
+	// A word across the start of a column belongs to the column so that
+	// right aligned values are read. There are no escape sequences
+	// and rows are not continued. Display width of characters is the
+	// same as Format. Tabs are expanded to every 8 columns.
+	FixedWidth
+
+	// Box is the syntax of tables drawn with borders, such as output of
+	// psql, mysql and tablewriter.
+	// Lines like "+----+----+", "----+----" and "├────┼────┤" are delimiter
+	// rows. '|', '│', '┃' and '║' are separators. Separators at both ends
+	// of rows are stripped. Footers such as "(2 rows)" end the table.
+	// When the header starts with a separator, a line which does not start
+	// with it also ends the table. There are no escape sequences and rows
+	// are not continued.
+	Box
+)
+
+// Orientation specifies how records are laid out in a table.
+type Orientation int
+
+const (
+	// Horizontal table has the header row and each following row is
+	// a record. This is the default.
+	Horizontal Orientation = iota
+
+	// Transposed table has column names in the first column and each
+	// following column is a record, such as "name | alice | bob".
+	Transposed
+
+	// AutoOrientation reads a table as Transposed when the header row
+	// is not bound to the struct but its first value is a column name of
+	// the struct. Otherwise the table is Horizontal. Tables decoded into
+	// map[string]string or []string are Horizontal.
+	AutoOrientation
+)
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{ts: newTableScanner(r)}
+}
+
+// SetErrorMode sets how DecodeAll handles a row which fails to be decoded.
+func (d *Decoder) SetErrorMode(m ErrorMode) {
+	d.mode = m
+}
+
+// SetNull sets a value regarded as null, such as "<nil>".
+// A field whose value equals to s is set to zero value, which is nil for
+// pointer. s is compared with the value after applying default option.
+// Empty s, which is the default, disables null.
+func (d *Decoder) SetNull(s string) {
+	d.null = s
+}
+
+// SetDialect sets syntax of table string.
+func (d *Decoder) SetDialect(dialect Dialect) {
+	d.ts.syn.dialect = dialect
+}
+
+// SetDocument sets whether the input is a document in which tables are
+// embedded, such as Markdown file with prose.
+// In document, a table starts with a header followed by a delimiter row,
+// both of which contain separator. The table ends at a line without separator.
+// Other lines are skipped. Title of a table is the nearest preceding ATX
+// heading such as "## users". Tables in fenced code blocks are skipped
+// unless SetFencedCode is called with true.
+func (d *Decoder) SetDocument(document bool) {
+	d.ts.document = document
+}
+
+// SetFencedCode sets whether tables in fenced code blocks of document are
+// read. See SetDocument.
+func (d *Decoder) SetFencedCode(fencedCode bool) {
+	d.ts.fencedCode = fencedCode
+}
+
+// SetSeparator sets separator of columns such as '\t', ',' or ';'.
+// Escape sequence of the separator is '\\' followed by it.
+// Default is '|'. '\\', 'n', '-', ':', line breaks and white spaces
+// other than '\t' are invalid, and decoding returns an error.
+func (d *Decoder) SetSeparator(sep rune) {
+	d.ts.syn.sep = sep
+}
+
+// SetOrientation sets how records are laid out in a table.
+// A transposed table is read entirely at the first decode.
+func (d *Decoder) SetOrientation(o Orientation) {
+	d.orientation = o
+}
+
+// SetComment sets a comment marker such as "#" or "//".
+// A line starting with the marker after white spaces is a comment line,
+// which is skipped and does not end the table even in continued rows.
+// The marker which is not escaped in a row starts a trailing comment.
+// A backslash followed by the first character of the marker, such as `\#`,
+// represents the character itself.
+// Empty s, which is the default, disables comments.
+//
+// Comment lines are checked before title lines, so "#" marker hides
+// titles like "## users". Use titles like "[users]" instead.
+func (d *Decoder) SetComment(s string) {
+	d.ts.syn.comment = s
+}
+
+// DecodeAll reads all the remaining rows of the table and appends them to
+// the slice pointed to by t. t should be a pointer to slice of struct,
+// map[string]string or []string. A map has header values as keys.
+// Slice of []string has the header as the first element.
+//
+// Unless error mode is StopOnError, errors of rows are collected and
+// decoding continues to the end of the table. Then the errors are returned
+// joined by errors.Join. Errors which are not caused by a row, such as
+// failure to parse the header, stop decoding regardless of error mode.
+func (d *Decoder) DecodeAll(t interface{}) error {
 	// vXxx represents a value. tXxx represents a type.
 	vPointer := reflect.ValueOf(t)
 	if vPointer.Kind() != reflect.Ptr {
@@ -46,56 +282,379 @@ func UnmarshalReader(s io.Reader, t interface{}) error {
 		return errors.New("table: value of interface{} is not a pointer of slice")
 	}
 
-	tStruct := tSlice.Elem()
+	var errs []error
+	vSlice := vPointer.Elem()
+	tElem := tSlice.Elem()
+	var decode func() (reflect.Value, error)
+	switch {
+	case tElem.Kind() == reflect.Struct:
+		decode = func() (reflect.Value, error) {
+			vStruct, err := d.decode(tElem)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			return vStruct.Elem(), nil
+		}
+	case tElem == stringMapType:
+		decode = d.decodeMap
+	case tElem == stringsType:
+		err := d.readHeader()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		vSlice.Set(reflect.Append(vSlice, reflect.ValueOf(append([]string(nil), d.header...))))
+		decode = d.decodeStrings
+	default:
+		return errors.New("table: value of interface{} is not a pointer of slice of struct, map[string]string or []string")
+	}
+
+	for {
+		pos := d.position()
+		vElem, err := decode()
+		if err == io.EOF {
+			return errors.Join(errs...)
+		}
+
+		if err != nil {
+			if d.mode == StopOnError {
+				return err
+			}
+
+			if !d.canContinue(pos) {
+				return errors.Join(append(errs, err)...)
+			}
+
+			errs = append(errs, err)
+			if d.mode == ZeroOnError {
+				vSlice.Set(reflect.Append(vSlice, reflect.Zero(tElem)))
+			}
+			continue
+		}
+
+		vSlice.Set(reflect.Append(vSlice, vElem))
+	}
+}
+
+var (
+	stringMapType = reflect.TypeOf(map[string]string(nil))
+	stringsType   = reflect.TypeOf([]string(nil))
+)
+
+// reset makes d ready to decode the next table.
+func (d *Decoder) reset() {
+	d.header, d.headerLine, d.tStruct, d.fields, d.err = nil, 0, nil, nil, nil
+	d.transposed, d.records, d.fieldLines, d.endLine = false, nil, nil, 0
+	if !d.ts.document {
+		// title of document is the nearest heading, which is kept.
+		d.ts.title = ""
+	}
+	d.ts.comments = nil
+	d.ts.align = nil
+	d.ts.columns = nil
+	d.ts.bordered = false
+}
+
+// position returns how far d has read, which increases when a line or
+// a buffered row is consumed.
+func (d *Decoder) position() int {
+	return d.ts.line + d.taken
+}
+
+// canContinue reports whether decoding can continue after an error
+// of decode which started at pos.
+func (d *Decoder) canContinue(pos int) bool {
+	// When nothing is consumed, following decode fails in the same way.
+	return d.err == nil && d.position() != pos
+}
+
+// Decode reads the next row of the table and stores it in the value pointed
+// to by v. v should be a pointer to struct.
+// Header is parsed at the first call and reused in following calls.
+// Decode returns io.EOF at the end of the table.
+func (d *Decoder) Decode(v interface{}) error {
+	// vXxx represents a value. tXxx represents a type.
+	vPointer := reflect.ValueOf(v)
+	if vPointer.Kind() != reflect.Ptr || vPointer.IsNil() {
+		return errors.New("table: value of interface{} is not a non-nil pointer")
+	}
+
+	tStruct := vPointer.Type().Elem()
 	if tStruct.Kind() != reflect.Struct {
-		return errors.New("table: value of interface{} is not a pointer of slice of struct")
+		return errors.New("table: value of interface{} is not a pointer of struct")
 	}
 
-	ts := newTableScanner(s)
-	header, err := parseHeader(ts)
+	vStruct, err := d.decode(tStruct)
 	if err != nil {
-		return fmt.Errorf("table: failed to parse header: %v", err)
+		return err
 	}
 
-	if header.cols() == 0 {
+	vPointer.Elem().Set(vStruct.Elem())
+	return nil
+}
+
+// decode reads the next row and unmarshals it into value of tStruct type.
+// When successful, this returns pointer to the value and nil.
+func (d *Decoder) decode(tStruct reflect.Type) (reflect.Value, error) {
+	if err := d.readHeader(); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if tStruct != d.tStruct {
+		fields, perr := indexFieldToColumn(tStruct, d.header)
+		if perr != nil && d.detectTransposed(tStruct) {
+			if err := d.transpose(); err != nil {
+				return reflect.Value{}, err
+			}
+
+			fields, perr = indexFieldToColumn(tStruct, d.header)
+		}
+
+		if perr != nil {
+			perr.Line = d.headerLine
+			return reflect.Value{}, fmt.Errorf("table: check header: %w", perr)
+		}
+
+		d.tStruct, d.fields = tStruct, fields
+	}
+
+	r, err := d.next()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	vStruct, perr := unmarshalStruct(tStruct, r, d.fields, d.null)
+	if perr != nil {
+		perr.Line = d.ts.rowLine
+		if i := d.header.index(perr.Column); d.transposed && i != -1 {
+			perr.Line = d.fieldLines[i]
+		}
+		return reflect.Value{}, fmt.Errorf("table: failed to unmarshal row: %w", perr)
+	}
+
+	return vStruct, nil
+}
+
+// decodeMap reads the next row and returns it as map[string]string
+// whose keys are header values.
+func (d *Decoder) decodeMap() (reflect.Value, error) {
+	r, err := d.next()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	m := make(map[string]string, r.cols())
+	for i, h := range d.header {
+		m[h] = r[i]
+	}
+
+	return reflect.ValueOf(m), nil
+}
+
+// decodeStrings reads the next row and returns it as []string.
+func (d *Decoder) decodeStrings() (reflect.Value, error) {
+	r, err := d.next()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.ValueOf([]string(r)), nil
+}
+
+// readHeader parses header unless it is already parsed.
+// This returns io.EOF if the table has no header.
+func (d *Decoder) readHeader() error {
+	if d.err != nil {
+		return d.err
+	}
+
+	if d.header != nil {
 		return nil
 	}
 
-	indices, err := indexFieldToColumn(tStruct, header)
+	if !validSeparator(d.ts.syn.separator()) {
+		d.err = fmt.Errorf("table: invalid separator %q", d.ts.syn.separator())
+		return d.err
+	}
+
+	header, err := parseHeader(d.ts)
 	if err != nil {
-		return fmt.Errorf("table: check header: %v", err)
+		d.err = fmt.Errorf("table: failed to parse header: %w", err)
+		return d.err
 	}
 
-	// table body
-	vSlice := vPointer.Elem()
+	if header.cols() == 0 {
+		d.err = io.EOF
+		return d.err
+	}
+
+	d.header, d.headerLine = header, d.ts.rowLine
+	if d.orientation == Transposed {
+		return d.transpose()
+	}
+
+	return nil
+}
+
+// detectTransposed reports whether the table should be read as Transposed
+// in AutoOrientation. It is called when the header is not bound to tStruct.
+func (d *Decoder) detectTransposed(tStruct reflect.Type) bool {
+	// body rows are already read as Horizontal.
+	if d.orientation != AutoOrientation || d.transposed || d.ts.rowLine != d.headerLine {
+		return false
+	}
+
+	fields, err := typeFields(tStruct)
+	if err != nil {
+		return false
+	}
+
+	for _, f := range fields {
+		if f.tag.name == d.header[0] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// transpose reads the remaining rows of the table and transposes them
+// with the header. The first column becomes the header and each following
+// column becomes a record.
+func (d *Decoder) transpose() error {
+	rows := []row{d.header}
+	lines := []int{d.headerLine}
 	for {
-		r, err := ts.mergedRow()
-		if err == io.EOF {
-			return nil
+		r, err := d.ts.mergedRow()
+		if err == io.EOF || (err == nil && r == nil) {
+			break
 		}
 
 		if err != nil {
-			return fmt.Errorf("table: failed to parse table body: %v", err)
+			d.err = fmt.Errorf("table: failed to parse table body: %w", err)
+			return d.err
 		}
 
-		if r == nil {
-			return nil
+		if d.ts.syn.dialect == Markdown {
+			r = r.fit(d.header.cols())
 		}
 
-		if r.cols() != header.cols() {
-			return fmt.Errorf("table: number of columns: header=%v body=%v", header.cols(), r.cols())
+		if r.cols() != d.header.cols() {
+			d.err = fmt.Errorf("table: %w", &ParseError{
+				Line: d.ts.rowLine,
+				Err:  fmt.Errorf("number of columns: first=%v row=%v", d.header.cols(), r.cols()),
+			})
+			return d.err
 		}
 
-		vStruct, err := unmarshalStruct(tStruct, r, indices)
-		if err != nil {
-			return fmt.Errorf("table: failed to unmarshal row: %v", err)
+		rows = append(rows, r)
+		lines = append(lines, d.ts.rowLine)
+		d.endLine = d.ts.line
+	}
+
+	header := make(row, len(rows))
+	records := make([]row, d.header.cols()-1)
+	for i, r := range rows {
+		header[i] = r[0]
+		for j := range records {
+			records[j] = append(records[j], r[j+1])
+		}
+	}
+
+	if len(rows) == 1 {
+		d.endLine = d.headerLine
+	}
+
+	d.header, d.records, d.fieldLines, d.transposed = header, records, lines, true
+	return nil
+}
+
+// next reads the next row of the table body.
+// This returns io.EOF at the end of the table.
+func (d *Decoder) next() (row, error) {
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+
+	if d.transposed {
+		if len(d.records) == 0 {
+			d.err = io.EOF
+			return nil, d.err
+		}
+
+		r := d.records[0]
+		d.records = d.records[1:]
+		d.taken++
+		return r, nil
+	}
+
+	r, err := d.ts.mergedRow()
+	if err == io.EOF || (err == nil && r == nil) {
+		d.err = io.EOF
+		return nil, d.err
+	}
+
+	if err != nil {
+		// the line which ends the table is already consumed.
+		if errors.Is(err, errTableEnded) {
+			d.err = io.EOF
 		}
+		return nil, fmt.Errorf("table: failed to parse table body: %w", err)
+	}
+
+	// GFM regards missing cells as empty and ignores excess cells.
+	if d.ts.syn.dialect == Markdown {
+		r = r.fit(d.header.cols())
+	}
+
+	if r.cols() != d.header.cols() {
+		return nil, fmt.Errorf("table: %w", &ParseError{
+			Line: d.ts.rowLine,
+			Err:  fmt.Errorf("number of columns: header=%v body=%v", d.header.cols(), r.cols()),
+		})
+	}
+
+	return r, nil
+}
+
+// All returns an iterator over rows of table string read from r.
+// Each row is unmarshalled into T, which should be a struct type.
+// Rows are read lazily. Stopping the iteration stops reading r.
+//
+// An error of a row is yielded with the second value and the iteration
+// continues to the next row. The iteration stops after an error
+// which is not caused by a row, such as failure to read r or
+// failure to parse the header.
+func All[T any](r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		d := NewDecoder(r)
+		for {
+			var v T
+			pos := d.position()
+			err := d.Decode(&v)
+			if err == io.EOF {
+				return
+			}
+
+			if !yield(v, err) {
+				return
+			}
 
-		vSlice.Set(reflect.Append(vSlice, vStruct.Elem()))
+			if err != nil && !d.canContinue(pos) {
+				return
+			}
+		}
 	}
 }
 
 func parseHeader(ts *tableScanner) (row, error) {
+	ts.inHeader = true
+	defer func() { ts.inHeader = false }()
 	for {
 		header, err := ts.mergedRow()
 		if err == io.EOF {
@@ -103,7 +662,7 @@ func parseHeader(ts *tableScanner) (row, error) {
 		}
 
 		if err != nil {
-			return nil, fmt.Errorf("get header: %v", err)
+			return nil, fmt.Errorf("get header: %w", err)
 		}
 
 		if header != nil {
@@ -112,13 +671,41 @@ func parseHeader(ts *tableScanner) (row, error) {
 	}
 }
 
+// errTableEnded is an error of a continued row followed by the end of the table.
+var errTableEnded = errors.New("row continues but the table ended")
+
 // tableScanner is a bufio.Scanner for table string.
 type tableScanner struct {
 	scanner *bufio.Scanner
+	line    int  // number of lines scanned so far
+	rowLine int  // line number of the first line of the last row
+	merged  bool // the last row is merged from multiple lines
+
+	titles   bool   // recognize title lines above header
+	inHeader bool   // scanning lines above header or header
+	title    string // title of the table
+
+	syn          *syntax
+	keepComments bool      // record comments in comments
+	comments     []Comment // comments of the table
+	align        []Alignment
+	columns      []int // display positions where columns start in FixedWidth
+	bordered     bool  // the header starts with a separator in Box
+
+	document   bool   // scan a document in which tables are embedded
+	fencedCode bool   // read tables in fenced code blocks of document
+	fence      string // marker of the fenced code block of the current line. Empty if none
+	prevFence  string // fence before the current line
+	fenceLine  bool   // the current line opens or closes a fenced code block
+
+	text   string // the current line
+	peeked bool   // the next line is read into next
+	next   string
+	nextOK bool // the next line exists
 }
 
 func newTableScanner(r io.Reader) *tableScanner {
-	return &tableScanner{bufio.NewScanner(r)}
+	return &tableScanner{scanner: bufio.NewScanner(r), syn: &syntax{}}
 }
 
 // mergedRow returns a row. If the row consists of multiple rows, they are merged.
@@ -127,34 +714,68 @@ func (ts *tableScanner) mergedRow() (row, error) {
 	var cont bool
 	for {
 		if !ts.scan() {
+			if err := ts.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("scanning: %v", err)
+			}
+
 			if cont {
-				return nil, fmt.Errorf("row continues but the file ended")
+				return nil, &ParseError{Line: ts.line, Err: errors.New("row continues but the file ended")}
 			}
 			return row, io.EOF
 		}
 
+		// comment lines neither end the table nor break continued rows.
+		if ts.syn.isComment(ts.text) {
+			ts.addComment(ts.syn.commentText(ts.text), false)
+			continue
+		}
+
+		if ts.inHeader && row == nil && !cont && ts.skipAboveHeader() {
+			continue
+		}
+
+		if ts.syn.dialect == Box && !ts.inHeader && ts.endsBox() {
+			return row, nil
+		}
+
+		// In document, a line which is not a row ends the table.
+		// The line is scanned again since it can be a heading or a fence.
+		if ts.document && !ts.inHeader && !strings.ContainsRune(ts.text, ts.syn.separator()) {
+			if cont {
+				return nil, &ParseError{Line: ts.line, Err: errTableEnded}
+			}
+			ts.unscan()
+			return row, nil
+		}
+
 		r, c, err := ts.row()
 		if err != nil {
-			return nil, fmt.Errorf("get row: %v", err)
+			return nil, err
 		}
 
 		if r == nil {
 			if cont {
-				return nil, fmt.Errorf("row continues but the table ended ")
+				return nil, &ParseError{Line: ts.line, Err: errTableEnded}
 			}
 			return row, nil
 		}
 
 		cont = c
-		if r.isDelim() {
+		if ts.syn.isDelim(r) {
+			if ts.syn.dialect == Markdown && ts.align == nil {
+				ts.align = alignments(r)
+			}
 			continue
 		}
 
 		if row == nil {
 			row = r
+			ts.rowLine = ts.line
+			ts.merged = false
 		} else {
+			ts.merged = true
 			if err := row.merge(r); err != nil {
-				return nil, fmt.Errorf("merging: %v", err)
+				return nil, &ParseError{Line: ts.line, Err: fmt.Errorf("merging: %v", err)}
 			}
 		}
 
@@ -164,61 +785,355 @@ func (ts *tableScanner) mergedRow() (row, error) {
 	}
 }
 
+// skipAboveHeader reports whether the current line above header is skipped.
+// Title lines are skipped. In document, lines other than a header followed
+// by a delimiter row are skipped.
+func (ts *tableScanner) skipAboveHeader() bool {
+	if ts.document {
+		if ts.fenceLine || (ts.fence != "" && !ts.fencedCode) {
+			return true
+		}
+
+		if title, ok := parseHeading(ts.text); ok && ts.fence == "" {
+			ts.title = title
+			return true
+		}
+
+		return !ts.startsTable()
+	}
+
+	if ts.titles {
+		if title, ok := parseTitle(ts.text, ts.syn.separator()); ok {
+			ts.title = title
+			return true
+		}
+	}
+
+	return false
+}
+
+// startsTable reports whether the current line is a header followed by
+// a delimiter row with the same number of columns.
+// Both lines should contain separator so that prose is not regarded as a table.
+func (ts *tableScanner) startsTable() bool {
+	sep := ts.syn.separator()
+	if !strings.ContainsRune(ts.text, sep) {
+		return false
+	}
+
+	header, _, _, err := ts.syn.parseRow(ts.text)
+	if err != nil || header == nil {
+		return false
+	}
+
+	next, ok := ts.peek()
+	if !ok || !strings.ContainsRune(next, sep) {
+		return false
+	}
+
+	delim, _, _, err := ts.syn.parseRow(next)
+	return err == nil && delim.cols() == header.cols() && ts.syn.isDelim(delim)
+}
+
 func (ts *tableScanner) scan() bool {
-	return ts.scanner.Scan()
+	if ts.peeked {
+		ts.peeked = false
+		if !ts.nextOK {
+			return false
+		}
+		ts.text = ts.next
+	} else {
+		if !ts.scanner.Scan() {
+			return false
+		}
+		ts.text = ts.scanner.Text()
+	}
+
+	ts.line++
+	if ts.document {
+		ts.prevFence = ts.fence
+		ts.updateFence()
+	}
+	return true
+}
+
+// unscan pushes the current line back so that the next scan returns it
+// again. It should not be called after peek.
+func (ts *tableScanner) unscan() {
+	ts.peeked, ts.next, ts.nextOK = true, ts.text, true
+	ts.line--
+	ts.fence = ts.prevFence
+}
+
+// peek returns the next line without consuming it.
+// Returns false if there is no next line.
+func (ts *tableScanner) peek() (string, bool) {
+	if !ts.peeked {
+		ts.peeked = true
+		ts.nextOK = ts.scanner.Scan()
+		ts.next = ts.scanner.Text()
+	}
+
+	return ts.next, ts.nextOK
+}
+
+// updateFence updates state of fenced code block with the current line.
+func (ts *tableScanner) updateFence() {
+	ts.fenceLine = false
+	s := trim(ts.text)
+	m := fenceOf(s)
+	if m == "" {
+		return
+	}
+
+	if ts.fence == "" {
+		ts.fence, ts.fenceLine = m, true
+		return
+	}
+
+	// closing fence consists of the same characters at least as long as opening one.
+	if m[0] == ts.fence[0] && len(m) >= len(ts.fence) && m == s {
+		ts.fence, ts.fenceLine = "", true
+	}
 }
 
 func (ts *tableScanner) row() (row, bool, error) {
-	return parseRow(ts.scanner.Text())
+	if ts.syn.dialect == FixedWidth {
+		return ts.fixedWidthRow(), false, nil
+	}
+
+	if ts.syn.dialect == Box {
+		if ts.inHeader && trim(ts.text) != "" && !isBorder(ts.text) {
+			first, _ := utf8.DecodeRuneInString(trim(ts.text))
+			ts.bordered = isBoxSeparator(first)
+		}
+		return parseBoxRow(ts.text, ts.bordered), false, nil
+	}
+
+	r, c, comment, err := ts.syn.parseLine(ts.text, ts.line)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if comment != "" {
+		ts.addComment(ts.syn.commentText(comment), true)
+	}
+
+	return r, c, nil
 }
 
-func indexFieldToColumn(tStruct reflect.Type, header row) ([]int, error) {
-	ret := make([]int, tStruct.NumField())
-	for i := 0; i < tStruct.NumField(); i++ {
-		tag := tStruct.Field(i).Tag.Get("table")
-		if tag == "" {
-			continue
+// addComment records comment at the current line if comments are kept.
+func (ts *tableScanner) addComment(text string, trailing bool) {
+	if ts.keepComments {
+		ts.comments = append(ts.comments, Comment{Line: ts.line, Text: text, Trailing: trailing})
+	}
+}
+
+// parseLine is like parseRow except that returned error is *ParseError
+// whose Line is line.
+func (syn *syntax) parseLine(s string, line int) (row, bool, string, error) {
+	r, c, comment, err := syn.parseRow(s)
+	if err != nil {
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			pe.Line = line
+			return nil, false, "", pe
 		}
 
-		index := header.index(tag)
+		return nil, false, "", &ParseError{Line: line, Err: err}
+	}
+
+	return r, c, comment, nil
+}
+
+// indexFieldToColumn returns fields of tStruct which have table tag
+// with indices of corresponding columns in header.
+func indexFieldToColumn(tStruct reflect.Type, header row) ([]field, *ParseError) {
+	fields, err := typeFields(tStruct)
+	if err != nil {
+		return nil, &ParseError{Err: fmt.Errorf("parsing tag: %v", err)}
+	}
+
+	for i, f := range fields {
+		index := header.index(f.tag.name)
 		if index == -1 {
-			return nil, fmt.Errorf("column '%s' not found in table", tag)
+			return nil, &ParseError{
+				Column: f.tag.name,
+				Field:  f.name,
+				Err:    errors.New("column not found in table"),
+			}
 		}
 
-		ret[i] = index
+		fields[i].col = index
 	}
-	return ret, nil
+
+	return fields, nil
 }
 
 // unmarshalStruct unmarshals r into value of tStruct type.
 // When successful, this returns pointer to the value and nil.
-// When failure, this returns zero-value of reflect.Value and non-nil error.
-func unmarshalStruct(tStruct reflect.Type, row row, indices []int) (reflect.Value, error) {
+// When failure, this returns zero-value of reflect.Value and non-nil error
+// whose Line is not set.
+// Fields whose value is null are left zero value unless null is empty.
+func unmarshalStruct(tStruct reflect.Type, row row, fields []field, null string) (reflect.Value, *ParseError) {
 	// Not using reflect.Zero because of "settability".
 	// See https://blog.golang.org/laws-of-reflection
 	vPointer := reflect.New(tStruct)
-	for fi := 0; fi < vPointer.Elem().NumField(); fi++ {
-		vField := vPointer.Elem().Field(fi)
-		tField := tStruct.Field(fi)
-		s := row[indices[fi]]
-		if reflect.PtrTo(tField.Type).Implements(unmarshalerType) {
-			if err := unmarshalUnmarshalerType(vField, s); err != nil {
-				return reflect.Value{}, fmt.Errorf("unmarshaling Unmarshaler: %v", err)
-			}
+	for _, f := range fields {
+		s := row[f.col]
+		if s == "" && f.tag.def != nil {
+			s = *f.tag.def
+		}
+
+		if null != "" && s == null {
 			continue
 		}
 
-		if err := unmarshalBasicType(vField, s); err != nil {
-			return reflect.Value{}, fmt.Errorf("unmarshaling basic type: %v", err)
+		if err := unmarshalValue(fieldByIndex(vPointer.Elem(), f.index), s, f.tag); err != nil {
+			return reflect.Value{}, &ParseError{
+				Column: f.tag.name,
+				Field:  f.name,
+				Value:  s,
+				Err:    err,
+			}
 		}
 	}
 
 	return vPointer, nil
 }
 
+// fieldByIndex is like reflect.Value.FieldByIndex except for allocating
+// nil pointers to embedded structs on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// unmarshalValue parses s and sets the value to v.
+// Unmarshaler is preferred to encoding.TextUnmarshaler,
+// which is preferred to basic types.
+// t is the tag of the struct field which v belongs to.
+func unmarshalValue(v reflect.Value, s string, t *tag) error {
+	pt := reflect.PtrTo(v.Type())
+	if pt.Implements(unmarshalerType) {
+		return unmarshalUnmarshalerType(v, s)
+	}
+
+	// time.Time is a basic type to apply layout.
+	if pt.Implements(textUnmarshalerType) && v.Type() != timeType {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	// Empty value is nil. Otherwise the pointed value is parsed.
+	if v.Kind() == reflect.Ptr {
+		if s == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		e := reflect.New(v.Type().Elem())
+		if err := unmarshalValue(e.Elem(), s, t); err != nil {
+			return err
+		}
+
+		v.Set(e)
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		return unmarshalList(v, s, t)
+	}
+
+	if v.Kind() == reflect.Map {
+		return unmarshalMap(v, s, t)
+	}
+
+	return unmarshalBasicType(v, s, t)
+}
+
+// unmarshalList parses s as elements separated by separator of t
+// and sets them to v, which is a slice or an array.
+// Each element is trimmed and parsed in the same manner as a value.
+// Empty s is nil slice or an array of zero values.
+func unmarshalList(v reflect.Value, s string, t *tag) error {
+	var elems []string
+	if s != "" {
+		for _, e := range strings.Split(s, t.separator()) {
+			elems = append(elems, trim(e))
+		}
+	}
+
+	if v.Kind() == reflect.Slice {
+		if elems == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+	} else if len(elems) > v.Len() {
+		return fmt.Errorf("number of elements: array=%v value=%v", v.Len(), len(elems))
+	}
+
+	for i, e := range elems {
+		if err := unmarshalValue(v.Index(i), e, t); err != nil {
+			return fmt.Errorf("element %d: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalMap parses s as entries separated by separator of t
+// and sets them to v, which is a map whose key is string.
+// Key and value of an entry are separated by key-value separator of t.
+// Each key and value is trimmed and value is parsed in the same manner
+// as a value. Empty s is nil map.
+func unmarshalMap(v reflect.Value, s string, t *tag) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("parsing %s: key is not string", v.Type())
+	}
+
+	if s == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	m := reflect.MakeMap(v.Type())
+	for _, e := range strings.Split(s, t.separator()) {
+		k, val, ok := strings.Cut(e, t.keyValueSeparator())
+		if !ok {
+			return fmt.Errorf("no key-value separator %q in entry %q", t.keyValueSeparator(), trim(e))
+		}
+
+		vKey := reflect.New(v.Type().Key()).Elem()
+		vKey.SetString(trim(k))
+		vValue := reflect.New(v.Type().Elem()).Elem()
+		if err := unmarshalValue(vValue, trim(val), t); err != nil {
+			return fmt.Errorf("key %q: %v", vKey, err)
+		}
+
+		m.SetMapIndex(vKey, vValue)
+	}
+
+	v.Set(m)
+	return nil
+}
+
 // unmarshalerType is an object represents type of Unmarshaler.
 var unmarshalerType = reflect.TypeOf(new(Unmarshaler)).Elem()
 
+// textUnmarshalerType is an object represents type of encoding.TextUnmarshaler.
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+
 func unmarshalUnmarshalerType(v reflect.Value, s string) error {
 	// calls Addr() for pointer receiver
 	m := v.Addr().MethodByName("UnmarshalTable")
@@ -230,7 +1145,29 @@ func unmarshalUnmarshalerType(v reflect.Value, s string) error {
 	return nil
 }
 
-func unmarshalBasicType(v reflect.Value, s string) error {
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+func unmarshalBasicType(v reflect.Value, s string, t *tag) error {
+	switch v.Type() {
+	case timeType:
+		tm, err := parseTime(s, t)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %v", v.Type(), err)
+		}
+		v.Set(reflect.ValueOf(tm))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %v", v.Type(), err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
 	switch k := v.Kind(); k {
 	case reflect.String:
 		v.SetString(s)
@@ -265,6 +1202,22 @@ func unmarshalBasicType(v reflect.Value, s string) error {
 	return nil
 }
 
+// parseTime parses s as time.Time with layout and location of t.
+// Default layout is time.RFC3339 and default location is UTC.
+func parseTime(s string, t *tag) (time.Time, error) {
+	layout := time.RFC3339
+	if t.layout != "" {
+		layout = t.layout
+	}
+
+	loc := time.UTC
+	if t.loc != nil {
+		loc = t.loc
+	}
+
+	return time.ParseInLocation(layout, s, loc)
+}
+
 // parseBasicTypeError is an error represents failure for parsing basic types string.
 type parseBasicTypeError struct {
 	kind  reflect.Kind
@@ -274,3 +1227,41 @@ type parseBasicTypeError struct {
 func (e parseBasicTypeError) Error() string {
 	return fmt.Sprintf("parsing %s: %v", e.kind, e.cause)
 }
+
+func (e parseBasicTypeError) Unwrap() error {
+	return e.cause
+}
+
+// ParseError is an error which occurs while parsing table string.
+// It can be retrieved from errors returned by this package with errors.As.
+type ParseError struct {
+	Line   int    // line number in input, starting at 1
+	Pos    int    // position in the line counted in runes, starting at 1. 0 if unknown
+	Column string // header column name. Empty if unknown
+	Field  string // struct field name. Empty if unknown
+	Value  string // cell text. Escape sequences are unescaped
+	Err    error  // underlying cause
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "line %d", e.Line)
+	if e.Pos > 0 {
+		fmt.Fprintf(&b, ":%d", e.Pos)
+	}
+
+	if e.Column != "" {
+		fmt.Fprintf(&b, ": column %q", e.Column)
+	}
+
+	if e.Field != "" {
+		fmt.Fprintf(&b, " (field %s)", e.Field)
+	}
+
+	fmt.Fprintf(&b, ": %v", e.Err)
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -1,9 +1,14 @@
 package table
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/netip"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type testRow struct {
@@ -33,6 +38,14 @@ func (o *okNg) UnmarshalTable(p []byte) error {
 	return fmt.Errorf("neither OK nor NG: %q", string(p))
 }
 
+func (o okNg) MarshalTable() ([]byte, error) {
+	if o {
+		return []byte("OK"), nil
+	}
+
+	return []byte("NG"), nil
+}
+
 func TestUnmarshal(t *testing.T) {
 	tests := []struct {
 		name string
@@ -176,6 +189,441 @@ ghi          |              ||           | 1.234       |            |
 	}
 }
 
+func TestUnmarshal_time(t *testing.T) {
+	type timeRow struct {
+		Default  time.Time     `table:"default"`
+		Layout   time.Time     `table:"layout,layout=2006/01/02 15:04"`
+		TZ       time.Time     `table:"tz,layout=2006/01/02 15:04,tz=Asia/Tokyo"`
+		Duration time.Duration `table:"duration"`
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	got, err := Parse[timeRow]([]byte(`
+default                   | layout           | tz               | duration
+2020-01-02T03:04:05+09:00 | 2020/01/02 03:04 | 2020/01/02 03:04 | 1h2m3.5s
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := timeRow{
+		time.Date(2020, 1, 2, 3, 4, 5, 0, tokyo),
+		time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC),
+		time.Date(2020, 1, 2, 3, 4, 0, 0, tokyo),
+		time.Hour + 2*time.Minute + 3500*time.Millisecond,
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("want 1 row, got %d", len(got))
+	}
+
+	if !got[0].Default.Equal(want.Default) || !got[0].Layout.Equal(want.Layout) ||
+		!got[0].TZ.Equal(want.TZ) || got[0].TZ.Location().String() != "Asia/Tokyo" || got[0].Duration != want.Duration {
+		t.Fatalf("want %v, got %v", want, got[0])
+	}
+}
+
+type pointerRow struct {
+	Int    *int       `table:"int"`
+	String *string    `table:"string"`
+	Time   *time.Time `table:"time"`
+	Custom *okNg      `table:"custom"`
+	Name   string     `table:"name"`
+}
+
+func TestUnmarshal_pointer(t *testing.T) {
+	got, err := Parse[pointerRow]([]byte(`
+name | int   | string | time                 | custom
+a    |       |        |                      |
+b    | -0x20 | abc    | 2020-01-02T03:04:05Z | OK
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, str, tm, c := -32, "abc", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), okNg(true)
+	want := []pointerRow{
+		{Name: "a"},
+		{&i, &str, &tm, &c, "b"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestDecoder_SetNull(t *testing.T) {
+	type nullRow struct {
+		Port   int    `table:"port,default=80"`
+		Int    *int   `table:"int"`
+		String string `table:"string,default=<nil>"`
+		Name   string `table:"name,default=a,b"`
+	}
+
+	d := NewDecoder(strings.NewReader(`
+port | int   | string | name
+     | <nil> |        |
+8080 | 1     | abc    | c
+<nil>| <nil> | <nil>  | <nil>
+`))
+	d.SetNull("<nil>")
+	var got []nullRow
+	if err := d.DecodeAll(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	i := 1
+	want := []nullRow{
+		{80, nil, "", "a,b"},
+		{8080, &i, "abc", "c"},
+		{},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestDecoder_SetComment(t *testing.T) {
+	type commentRow struct {
+		Name string `table:"name"`
+		Tag  string `table:"tag"`
+	}
+
+	d := NewDecoder(strings.NewReader(`
+// users
+name | tag
+---- | ---
+abc  | \//1 // trailing comment
+
+  // comment line does not end the table
+def  | x \ // continued
+// comment line in continued row
+     | y
+`))
+	d.SetComment("//")
+	var got []commentRow
+	if err := d.DecodeAll(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []commentRow{{"abc", "//1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestDecoder_SetDialect_markdown(t *testing.T) {
+	type mdRow struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+		Path string `table:"path"`
+	}
+
+	d := NewDecoder(strings.NewReader(`
+| name  | age | path        |
+| ----- | --: | :---------- |
+| alice | 20  | C:\Users\a  |
+| bob   | 30  | a\|b        |
+| carol | 40  |
+| dave  | 50  | /d          | extra |
+`))
+	d.SetDialect(Markdown)
+	var got []mdRow
+	if err := d.DecodeAll(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []mdRow{{"alice", 20, `C:\Users\a`}, {"bob", 30, "a|b"}, {"carol", 40, ""}, {"dave", 50, "/d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestDecoder_SetSeparator(t *testing.T) {
+	type sepRow struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	tests := []struct {
+		sep rune
+		s   string
+	}{
+		{'\t', "name\tage\nalice\t20\nb\\\tob\t\\\n\t30\n"},
+		{',', "name, age\n---, ---\nalice, 20\nb\\,ob, \\\n, 30\n"},
+		{';', "name;age\nalice ; 20\nb\\;ob;\\\n;30\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sep), func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.s))
+			d.SetSeparator(tt.sep)
+			var got []sepRow
+			if err := d.DecodeAll(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []sepRow{{"alice", 20}, {"b" + string(tt.sep) + "ob", 30}}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+		})
+	}
+
+	for _, sep := range []rune{' ', 'n', '-', ':'} {
+		d := NewDecoder(strings.NewReader("name n age\nx n 1\n"))
+		d.SetSeparator(sep)
+		var got []sepRow
+		if err := d.DecodeAll(&got); err == nil {
+			t.Fatalf("%q: error should be non-nil", sep)
+		}
+	}
+}
+
+func TestDecoder_SetOrientation(t *testing.T) {
+	type user struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	transposed := `
+name | alice | bob
+---- | ----- | ---
+age  | 20    | 30
+
+ignored lines...
+`
+	horizontal := `
+name  | age
+----- | ---
+alice | 20
+bob   | 30
+`
+	tests := []struct {
+		name        string
+		orientation Orientation
+		s           string
+	}{
+		{"transposed", Transposed, transposed},
+		{"auto transposed", AutoOrientation, transposed},
+		{"auto horizontal", AutoOrientation, horizontal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.s))
+			d.SetOrientation(tt.orientation)
+			var got []user
+			if err := d.DecodeAll(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []user{{"alice", 20}, {"bob", 30}}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("want %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDecoder_SetOrientation_error(t *testing.T) {
+	type user struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	d := NewDecoder(strings.NewReader(`name | alice | bob | carol
+age  | x     | 30  | y
+`))
+	d.SetOrientation(Transposed)
+	d.SetErrorMode(SkipOnError)
+	var got []user
+	err := d.DecodeAll(&got)
+	if err == nil {
+		t.Fatal("error should be non-nil")
+	}
+
+	want := []user{{"bob", 30}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Line != 2 || pe.Column != "age" {
+		t.Fatalf("want error at line 2 column age, got %v", err)
+	}
+
+	d = NewDecoder(strings.NewReader(`name | alice | bob
+age  | 20
+`))
+	d.SetOrientation(Transposed)
+	if err := d.DecodeAll(&got); err == nil {
+		t.Fatal("error should be non-nil")
+	}
+}
+
+type listRow struct {
+	Strings  []string        `table:"strings"`
+	Ints     [3]int          `table:"ints,sep=;"`
+	Pointers []*int          `table:"pointers"`
+	Customs  []okNg          `table:"customs,sep= "`
+	Times    []time.Time     `table:"times,layout=2006-01-02"`
+	Empty    []time.Duration `table:"empty"`
+}
+
+func TestUnmarshal_list(t *testing.T) {
+	got, err := Parse[listRow]([]byte(`
+strings     | ints   | pointers | customs  | times                 | empty
+a, b ,c d   | 1; 2   | 1,,3     | OK NG OK | 2020-01-02,2021-03-04 |
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	one, three := 1, 3
+	want := []listRow{{
+		[]string{"a", "b", "c d"},
+		[3]int{1, 2, 0},
+		[]*int{&one, nil, &three},
+		[]okNg{true, false, true},
+		[]time.Time{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+		nil,
+	}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+type mapRow struct {
+	Labels  map[string]string        `table:"labels"`
+	Ports   map[string]int           `table:"ports,sep=;,kvsep=:"`
+	Timeout map[string]time.Duration `table:"timeout,sep=;"`
+	Empty   map[string]bool          `table:"empty"`
+	Pointer map[string]*okNg         `table:"pointer"`
+}
+
+func TestUnmarshal_map(t *testing.T) {
+	got, err := Parse[mapRow]([]byte(`
+labels           | ports              | timeout       | empty | pointer
+k1=v1, k2 = v=2  | http:80; https:443 | a=1s; b=1m    |       | x=OK, y=
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := okNg(true)
+	want := []mapRow{{
+		map[string]string{"k1": "v1", "k2": "v=2"},
+		map[string]int{"http": 80, "https": 443},
+		map[string]time.Duration{"a": time.Second, "b": time.Minute},
+		nil,
+		map[string]*okNg{"x": &ok, "y": nil},
+	}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestUnmarshal_nested(t *testing.T) {
+	got, err := Parse[nestedRow]([]byte(`
+id | in.method | in.path | want.status | want.time
+1  | GET       | /       | 200         | 2020-01-02T03:04:05Z
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []nestedRow{{
+		nestedBase: nestedBase{ID: 1},
+		Input:      nestedReq{"GET", "/"},
+		Want:       nestedResp{200, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestUnmarshal_embeddedPointer(t *testing.T) {
+	got, err := Parse[nestedPtrRow]([]byte("x | y\n1 | 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []nestedPtrRow{{&NestedPtr{"1"}, "2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestUnmarshal_schemaless(t *testing.T) {
+	src := `
+name | age | note
+---- | --- | ----
+abc  | 12  | x\|y
+def  |     | \
+     | 34  | z
+`
+
+	t.Run("map", func(t *testing.T) {
+		var got []map[string]string
+		if err := Unmarshal([]byte(src), &got); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []map[string]string{
+			{"name": "abc", "age": "12", "note": "x|y"},
+			{"name": "def", "age": "34", "note": "z"},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		var got [][]string
+		if err := Unmarshal([]byte(src), &got); err != nil {
+			t.Fatal(err)
+		}
+
+		want := [][]string{
+			{"name", "age", "note"},
+			{"abc", "12", "x|y"},
+			{"def", "34", "z"},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var got [][]string
+		if err := Unmarshal(nil, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if got != nil {
+			t.Fatalf("want nil, got %v", got)
+		}
+	})
+
+	t.Run("number of columns", func(t *testing.T) {
+		var got []map[string]string
+		if err := Unmarshal([]byte("a | b\nc"), &got); err == nil {
+			t.Fatal("error should be non-nil")
+		}
+	})
+}
+
 func TestUnmarshal_error(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -273,13 +721,13 @@ abc          | OK           || 302       | 1.234       | true       | 7890
 			&[]*testRow{},
 		},
 		{
-			"table:pointer to slice of non-struct",
+			"table:pointer to slice of int",
 			`
 string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
 ------------ | ------------ || --------- | ----------- | ---------- | ---------- | ------------- | ------------
 abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd        | あいうえお
 `,
-			&[][]string{},
+			&[]int{},
 		},
 		{
 			"different number of columns in header",
@@ -328,6 +776,112 @@ abc          | OK           || 302       | 1.234       | ?          | 7890
 `,
 			&[]testRow{},
 		},
+		{
+			"TextUnmarshaler",
+			`
+addr
+192.0.2.256
+`,
+			&[]struct {
+				Addr netip.Addr `table:"addr"`
+			}{},
+		},
+		{
+			"time",
+			`
+t
+2020-01-02
+`,
+			&[]struct {
+				T time.Time `table:"t"`
+			}{},
+		},
+		{
+			"duration",
+			`
+d
+1
+`,
+			&[]struct {
+				D time.Duration `table:"d"`
+			}{},
+		},
+		{
+			"unknown tz",
+			`
+t
+2020-01-02
+`,
+			&[]struct {
+				T time.Time `table:"t,tz=Unknown/Location"`
+			}{},
+		},
+		{
+			"pointer",
+			`
+int | string | time | custom | name
+?   |        |      |        | a
+`,
+			&[]pointerRow{},
+		},
+		{
+			"null is not set",
+			`
+int
+<nil>
+`,
+			&[]struct {
+				I *int `table:"int"`
+			}{},
+		},
+		{
+			"too many elements of array",
+			`
+ints
+1, 2, 3
+`,
+			&[]struct {
+				I [2]int `table:"ints"`
+			}{},
+		},
+		{
+			"invalid element",
+			`
+ints
+1, ?, 3
+`,
+			&[]struct {
+				I []int `table:"ints"`
+			}{},
+		},
+		{
+			"no key-value separator",
+			`
+map
+k1=v1, k2
+`,
+			&[]struct {
+				M map[string]string `table:"map"`
+			}{},
+		},
+		{
+			"map key is not string",
+			`
+map
+1=1
+`,
+			&[]struct {
+				M map[int]int `table:"map"`
+			}{},
+		},
+		{
+			"nested column",
+			`
+id | in.method | want.status | want.time
+1  | GET       | 200         | 2020-01-02T03:04:05Z
+`,
+			&[]nestedRow{},
+		},
 		{
 			"continue but table end",
 			`
@@ -354,6 +908,346 @@ ignored lines...
 	}
 }
 
+const parseStr = `
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+------------ | ------------ || --------- | ----------- | ---------- | ---------- | ------------- | ------------
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd        | あいうえお
+             | NG           || -0x20     | -5          | F          | 3333       | \|\\n\|       | 日本語
+`
+
+func TestParse(t *testing.T) {
+	want := []testRow{
+		{true, 302, 7890, 1.234, "abc", "あいうえお", true, "abc\nd"},
+		{false, -0x20, 3333, -5, "", "日本語", false, "|\\n|"},
+	}
+
+	got, err := Parse[testRow]([]byte(parseStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	if got := MustParse[testRow]([]byte(parseStr)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	if _, err := Parse[int]([]byte(parseStr)); err == nil {
+		t.Fatal("error should be non-nil")
+	}
+
+	if _, err := ParseReader[testRow](strings.NewReader("int value\n?")); err == nil {
+		t.Fatal("error should be non-nil")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustParse should panic")
+		}
+	}()
+	MustParse[*testRow]([]byte(parseStr))
+}
+
+func TestUnmarshal_parseError(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want ParseError
+	}{
+		{
+			"basic type",
+			`
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+------------ | ------------ || --------- | ----------- | ---------- | ---------- | ------------- | ------------
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd        | あいうえお
+abc          | OK           || ?         | 1.234       | true       | 7890       | abc\nd        | あいうえお
+`,
+			ParseError{Line: 5, Column: "int value", Field: "Int", Value: "?"},
+		},
+		{
+			"Unmarshaler in continued row",
+			`
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd        | あいうえお \
+abc          | OK           ||           |             |            |            |               |
+`,
+			ParseError{Line: 3, Column: "custom value", Field: "Custom", Value: "OK OK"},
+		},
+		{
+			"illegal escape",
+			`
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\d        | あいうえお
+`,
+			ParseError{Line: 3, Pos: 87},
+		},
+		{
+			"number of columns",
+			`
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd
+`,
+			ParseError{Line: 3},
+		},
+		{
+			"required column",
+			`
+
+string value | custom value || int value | float value | bool value | uint value | escaped value
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd
+`,
+			ParseError{Line: 3, Column: "文字列 の 値", Field: "Mojiretsu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var table []testRow
+			err := Unmarshal([]byte(tt.s), &table)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error should be *ParseError: %v", err)
+			}
+
+			if pe.Err == nil {
+				t.Fatal("cause should be non-nil")
+			}
+
+			got := *pe
+			got.Err = nil
+			if got != tt.want {
+				t.Fatalf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+------------ | ------------ || --------- | ----------- | ---------- | ---------- | ------------- | ------------
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd        | あいうえお
+             | NG           || ?         | -5          | F          | 3333       | \|\\n\|       | 日本語
+def          | NG           || 3         | 4.5         | F          | 6          | \\           | いろは \
+ghi          |              ||           |             |            |            |               |
+
+ignored lines...
+`))
+
+	want := []testRow{
+		{true, 302, 7890, 1.234, "abc", "あいうえお", true, "abc\nd"},
+		{false, 3, 6, 4.5, "def ghi", "いろは", false, "\\"},
+	}
+
+	var got []testRow
+	for {
+		var r testRow
+		err := d.Decode(&r)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			// the second row has invalid int value. decoding continues.
+			continue
+		}
+
+		got = append(got, r)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	var r testRow
+	if err := d.Decode(&r); err != io.EOF {
+		t.Fatalf("want io.EOF, got %v", err)
+	}
+}
+
+func TestDecoder_DecodeAll(t *testing.T) {
+	src := `
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+------------ | ------------ || --------- | ----------- | ---------- | ---------- | ------------- | ------------
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd        | あいうえお
+             | NG           || ?         | -5          | F          | 3333       | \|\\n\|       | 日本語
+def          | ??           || 3         | 4.5         | F          | 6          | \\           | いろは
+ghi          | NG           || 3         | 4.5         | F          | 6          | \\           | にほへ
+`
+	abc := testRow{true, 302, 7890, 1.234, "abc", "あいうえお", true, "abc\nd"}
+	ghi := testRow{false, 3, 6, 4.5, "ghi", "にほへ", false, "\\"}
+
+	tests := []struct {
+		name     string
+		mode     ErrorMode
+		want     []testRow
+		wantErrs int
+	}{
+		{"stop", StopOnError, []testRow{abc}, 1},
+		{"skip", SkipOnError, []testRow{abc, ghi}, 2},
+		{"zero", ZeroOnError, []testRow{abc, {}, {}, ghi}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(src))
+			d.SetErrorMode(tt.mode)
+			var got []testRow
+			err := d.DecodeAll(&got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+
+			errs := []error{err}
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			}
+
+			if len(errs) != tt.wantErrs {
+				t.Fatalf("want %d errors, got %d: %v", tt.wantErrs, len(errs), err)
+			}
+
+			for i, e := range errs {
+				var pe *ParseError
+				if !errors.As(e, &pe) {
+					t.Fatalf("error should be *ParseError: %v", e)
+				}
+
+				if pe.Line != 5+i {
+					t.Fatalf("want line %d, got %d", 5+i, pe.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoder_DecodeAll_continuedToEnd(t *testing.T) {
+	type abc struct {
+		A string `table:"a"`
+		B string `table:"b"`
+		C string `table:"c"`
+	}
+
+	for _, mode := range []ErrorMode{StopOnError, SkipOnError, ZeroOnError} {
+		d := NewDecoder(strings.NewReader("a | b | c\n1 | 2 | 3 \\\n\nx | y | z\n"))
+		d.SetErrorMode(mode)
+		var got []abc
+		err := d.DecodeAll(&got)
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Line != 3 {
+			t.Fatalf("mode %d: want error at line 3, got %v", mode, err)
+		}
+
+		// the line after the table is not decoded.
+		for _, r := range got {
+			if r.A == "x" {
+				t.Fatalf("mode %d: decoded beyond the table: %v", mode, got)
+			}
+		}
+	}
+}
+
+func TestDecoder_Decode_error(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"nil", nil},
+		{"struct", testRow{}},
+		{"nil pointer", (*testRow)(nil)},
+		{"pointer to slice", &[]testRow{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(string(tableStr)))
+			if err := d.Decode(tt.v); err == nil || err == io.EOF {
+				t.Fatalf("error should be non-nil and non-EOF: %v", err)
+			}
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	src := `
+string value | custom value || int value | float value | bool value | uint value | escaped value | 文字列 の 値
+------------ | ------------ || --------- | ----------- | ---------- | ---------- | ------------- | ------------
+abc          | OK           || 302       | 1.234       | true       | 7890       | abc\nd        | あいうえお
+             | NG           || ?         | -5          | F          | 3333       | \|\\n\|       | 日本語
+def          | NG           || 3         | 4.5         | F          | 6          | \\           | いろは
+`
+
+	t.Run("all", func(t *testing.T) {
+		want := []testRow{
+			{true, 302, 7890, 1.234, "abc", "あいうえお", true, "abc\nd"},
+			{false, 3, 6, 4.5, "def", "いろは", false, "\\"},
+		}
+
+		var got []testRow
+		var errs int
+		for r, err := range All[testRow](strings.NewReader(src)) {
+			if err != nil {
+				errs++
+				continue
+			}
+
+			got = append(got, r)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+
+		if errs != 1 {
+			t.Fatalf("want 1 error, got %d", errs)
+		}
+	})
+
+	t.Run("break", func(t *testing.T) {
+		n := 0
+		for range All[testRow](strings.NewReader(src)) {
+			n++
+			break
+		}
+
+		if n != 1 {
+			t.Fatalf("want 1 iteration, got %d", n)
+		}
+	})
+
+	t.Run("non-struct", func(t *testing.T) {
+		n := 0
+		for _, err := range All[int](strings.NewReader(src)) {
+			if err == nil {
+				t.Fatal("error should be non-nil")
+			}
+			n++
+		}
+
+		if n != 1 {
+			t.Fatalf("want 1 iteration, got %d", n)
+		}
+	})
+
+	t.Run("header error", func(t *testing.T) {
+		n := 0
+		for _, err := range All[testRow](strings.NewReader(`a | b \`)) {
+			if err == nil {
+				t.Fatal("error should be non-nil")
+			}
+			n++
+		}
+
+		if n != 1 {
+			t.Fatalf("want 1 iteration, got %d", n)
+		}
+	})
+}
+
 func BenchmarkUnmarshal(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		var tbl []testRow
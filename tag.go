@@ -0,0 +1,205 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// field is a struct field bound to a column.
+type field struct {
+	index []int  // index sequence of the struct field for reflect.Value.FieldByIndex
+	name  string // name of the struct field. Names of nesting fields are prepended
+	tag   *tag
+	col   int // index of the column. -1 if not bound yet
+}
+
+// tag is a parsed struct field tag.
+// Tag format is as follows:
+//
+//	`table:"column name,option=value,option=value"`
+type tag struct {
+	name   string         // column name
+	layout string         // layout of time.Time. Empty for default
+	loc    *time.Location // location of time.Time. nil for default
+	def    *string        // value used when the value is empty. nil for none
+	sep    string         // separator of elements of slice, array and map. Empty for default
+	kvsep  string         // separator of key and value of map. Empty for default
+}
+
+// separator returns separator of elements of slice, array and map.
+func (t *tag) separator() string {
+	if t.sep == "" {
+		return ","
+	}
+
+	return t.sep
+}
+
+// keyValueSeparator returns separator of key and value of map.
+func (t *tag) keyValueSeparator() string {
+	if t.kvsep == "" {
+		return "="
+	}
+
+	return t.kvsep
+}
+
+// tagOptions are names of options in struct field tag.
+// A segment which does not start with these names followed by '=' is
+// regarded as a part of the previous segment. So column names and
+// option values can contain ','.
+var tagOptions = []string{"layout", "tz", "default", "sep", "kvsep"}
+
+// parseTag parses struct field tag s.
+func parseTag(s string) (*tag, error) {
+	segs := strings.Split(s, ",")
+	t := &tag{name: segs[0]}
+	var key, value string
+	set := func() error {
+		if key == "" {
+			return nil
+		}
+
+		switch key {
+		case "layout":
+			t.layout = value
+		case "tz":
+			loc, err := time.LoadLocation(value)
+			if err != nil {
+				return fmt.Errorf("option tz: %v", err)
+			}
+			t.loc = loc
+		case "default":
+			def := value
+			t.def = &def
+		case "sep":
+			t.sep = value
+		case "kvsep":
+			t.kvsep = value
+		}
+
+		return nil
+	}
+
+	for _, seg := range segs[1:] {
+		k, v, ok := optionOf(seg)
+		if !ok {
+			if key == "" {
+				t.name += "," + seg
+			} else {
+				value += "," + seg
+			}
+			continue
+		}
+
+		if err := set(); err != nil {
+			return nil, err
+		}
+
+		key, value = k, v
+	}
+
+	if err := set(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// optionOf splits seg into option name and value.
+// Returns false if seg is not an option.
+func optionOf(seg string) (string, string, bool) {
+	k, v, ok := strings.Cut(seg, "=")
+	if !ok {
+		return "", "", false
+	}
+
+	k = strings.TrimSpace(k)
+	for _, o := range tagOptions {
+		if k == o {
+			return k, v, true
+		}
+	}
+
+	return "", "", false
+}
+
+// typeFields returns fields of tStruct which have table tag.
+//
+// A field of struct type with table tag is nested. Its fields are bound to
+// columns whose names are joined with '.' such as "in.method".
+// An embedded struct or pointer to struct without table tag is flattened.
+// Its fields are treated as if they were fields of tStruct.
+// Struct types which implement Unmarshaler, encoding.TextUnmarshaler,
+// Marshaler or encoding.TextMarshaler and time.Time are not nested.
+func typeFields(tStruct reflect.Type) ([]field, error) {
+	return appendFields(nil, tStruct, nil, "", "")
+}
+
+// appendFields appends fields of tStruct to fields.
+// index, name and column are those of the field nesting tStruct.
+func appendFields(fields []field, tStruct reflect.Type, index []int, name, column string) ([]field, error) {
+	for i := 0; i < tStruct.NumField(); i++ {
+		sf := tStruct.Field(i)
+		fi := append(index[:len(index):len(index)], i)
+		fn := name + sf.Name
+		s := sf.Tag.Get("table")
+		if s == "" {
+			et := sf.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+
+			if sf.Anonymous && isNested(et) {
+				n := len(fields)
+				var err error
+				fields, err = appendFields(fields, et, fi, fn+".", column)
+				if err != nil {
+					return nil, err
+				}
+
+				// nil pointer can not be allocated through unexported field.
+				if len(fields) > n && et != sf.Type && !sf.IsExported() {
+					return nil, fmt.Errorf("field %s: embedded pointer to unexported struct", fn)
+				}
+			}
+			continue
+		}
+
+		t, err := parseTag(s)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", fn, err)
+		}
+
+		t.name = column + t.name
+		if isNested(sf.Type) {
+			fields, err = appendFields(fields, sf.Type, fi, fn+".", t.name+".")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		fields = append(fields, field{index: fi, name: fn, tag: t, col: -1})
+	}
+
+	return fields, nil
+}
+
+// isNested reports whether fields of t are bound to columns.
+func isNested(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+
+	pt := reflect.PtrTo(t)
+	for _, it := range []reflect.Type{unmarshalerType, textUnmarshalerType, marshalerType, textMarshalerType} {
+		if pt.Implements(it) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,144 @@
+package table
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTag(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	def80, empty := "80", ""
+	tests := []struct {
+		s    string
+		want tag
+	}{
+		{"a", tag{name: "a"}},
+		{"a b", tag{name: "a b"}},
+		{"a,b", tag{name: "a,b"}},
+		{"a=b", tag{name: "a=b"}},
+		{"a,layout=2006-01-02", tag{name: "a", layout: "2006-01-02"}},
+		{"a, layout=2006-01-02", tag{name: "a", layout: "2006-01-02"}},
+		{"a,b,layout=Mon, 02 Jan 2006", tag{name: "a,b", layout: "Mon, 02 Jan 2006"}},
+		{"a,tz=Asia/Tokyo,layout=15:04", tag{name: "a", layout: "15:04", loc: tokyo}},
+		{"a,unknown=x", tag{name: "a,unknown=x"}},
+		{"a,default=80", tag{name: "a", def: &def80}},
+		{"a,default=", tag{name: "a", def: &empty}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseTag(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("want %+v, got %+v", tt.want, *got)
+			}
+		})
+	}
+}
+
+func TestParseTag_error(t *testing.T) {
+	tests := []string{
+		"a,tz=Unknown/Location",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			if _, err := parseTag(tt); err == nil {
+				t.Fatal("error should be non-nil")
+			}
+		})
+	}
+}
+
+type nestedReq struct {
+	Method string `table:"method"`
+	Path   string `table:"path"`
+}
+
+type nestedResp struct {
+	Status int       `table:"status"`
+	Time   time.Time `table:"time"`
+}
+
+type nestedBase struct {
+	ID   int `table:"id"`
+	note string
+}
+
+type nestedRow struct {
+	nestedBase
+	Input  nestedReq  `table:"in"`
+	Want   nestedResp `table:"want"`
+	Ignore nestedReq
+}
+
+func TestTypeFields(t *testing.T) {
+	fields, err := typeFields(reflect.TypeOf(nestedRow{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type nameIndex struct {
+		column, name string
+		index        []int
+	}
+
+	want := []nameIndex{
+		{"id", "nestedBase.ID", []int{0, 0}},
+		{"in.method", "Input.Method", []int{1, 0}},
+		{"in.path", "Input.Path", []int{1, 1}},
+		{"want.status", "Want.Status", []int{2, 0}},
+		{"want.time", "Want.Time", []int{2, 1}},
+	}
+
+	var got []nameIndex
+	for _, f := range fields {
+		got = append(got, nameIndex{f.tag.name, f.name, f.index})
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+// NestedPtr is exported so that it can be embedded as pointer.
+type NestedPtr struct {
+	X string `table:"x"`
+}
+
+type nestedPtrRow struct {
+	*NestedPtr
+	Y string `table:"y"`
+}
+
+func TestTypeFields_embeddedPointer(t *testing.T) {
+	fields, err := typeFields(reflect.TypeOf(nestedPtrRow{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range fields {
+		got = append(got, f.tag.name)
+	}
+
+	if want := []string{"x", "y"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	type unexported struct {
+		*nestedBase
+	}
+
+	if _, err := typeFields(reflect.TypeOf(unexported{})); err == nil {
+		t.Fatal("error should be non-nil")
+	}
+}